@@ -0,0 +1,109 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"go-cache/cacher/store"
+)
+
+// defaultParallelism 拆分后默认同时执行的批次数量
+const defaultParallelism = 4
+
+// forEachChunk 将keys按size拆分，以最多parallelism个并发执行fn
+// size<=0或只有一个批次时直接在当前goroutine执行；任一批次出错时取消其余批次并返回第一个错误
+func forEachChunk(ctx context.Context, keys []string, size, parallelism int, fn func(ctx context.Context, chunk []string) error) error {
+	return store.ForChunks(ctx, len(keys), size, parallelism, func(ctx context.Context, start, end int) error {
+		return fn(ctx, keys[start:end])
+	})
+}
+
+// mgetStore 从Store批量读取到dstMap，键超过BatchSize时分批并发读取后合并
+// 返回: Store报告的无法读取的键(没有时为nil), 错误信息
+func (c *CacherImpl) mgetStore(ctx context.Context, keys []string, dstMap interface{}) (*store.BatchError, error) {
+	if c.opts.BatchSize <= 0 || len(keys) <= c.opts.BatchSize {
+		var batchErr *store.BatchError
+		if err := c.store.MGet(ctx, keys, dstMap); err != nil && !errors.As(err, &batchErr) {
+			return nil, err
+		}
+		return batchErr, nil
+	}
+
+	// 每批读取到同类型的临时map，再在锁内合并
+	mapValue := reflect.ValueOf(dstMap).Elem()
+	batchErr := &store.BatchError{}
+	var mutex sync.Mutex
+	err := forEachChunk(ctx, keys, c.opts.BatchSize, c.opts.Parallelism, func(ctx context.Context, chunk []string) error {
+		chunkMap := reflect.New(mapValue.Type())
+		chunkMap.Elem().Set(reflect.MakeMap(mapValue.Type()))
+
+		var chunkErr *store.BatchError
+		if err := c.store.MGet(ctx, chunk, chunkMap.Interface()); err != nil && !errors.As(err, &chunkErr) {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		entries := chunkMap.Elem().MapRange()
+		for entries.Next() {
+			mapValue.SetMapIndex(entries.Key(), entries.Value())
+		}
+		if chunkErr != nil {
+			for key, err := range chunkErr.Errors {
+				batchErr.Add(key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(batchErr.Errors) == 0 {
+		return nil, nil
+	}
+	return batchErr, nil
+}
+
+// callFallback 调用批量回退函数，键超过FallbackBatchSize时分批并发调用后合并结果
+func (c *CacherImpl) callFallback(ctx context.Context, keys []string, fallback BatchFallbackFunc) (map[string]interface{}, error) {
+	if c.opts.FallbackBatchSize <= 0 || len(keys) <= c.opts.FallbackBatchSize {
+		return fallback(ctx, keys)
+	}
+
+	results := make(map[string]interface{}, len(keys))
+	var mutex sync.Mutex
+	err := forEachChunk(ctx, keys, c.opts.FallbackBatchSize, c.opts.Parallelism, func(ctx context.Context, chunk []string) error {
+		chunkResults, err := fallback(ctx, chunk)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for key, value := range chunkResults {
+			results[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// forEachItemChunk 将items按BatchSize拆分后并发执行fn，用于分批写入
+func (c *CacherImpl) forEachItemChunk(ctx context.Context, items map[string]interface{}, fn func(ctx context.Context, chunk map[string]interface{}) error) error {
+	if c.opts.BatchSize <= 0 || len(items) <= c.opts.BatchSize {
+		return fn(ctx, items)
+	}
+
+	return forEachChunk(ctx, mapKeys(items), c.opts.BatchSize, c.opts.Parallelism, func(ctx context.Context, keys []string) error {
+		chunk := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			chunk[key] = items[key]
+		}
+		return fn(ctx, chunk)
+	})
+}
@@ -2,7 +2,10 @@ package cacher
 
 import (
 	"context"
+	"iter"
 	"time"
+
+	"go-cache/cacher/store"
 )
 
 // FallbackFunc 回退函数类型
@@ -17,10 +20,46 @@ type FallbackFunc func(ctx context.Context, key string) (interface{}, bool, erro
 // 返回: 键值映射, 错误信息
 type BatchFallbackFunc func(ctx context.Context, keys []string) (map[string]interface{}, error)
 
+// CounterFallbackFunc 计数器回退函数类型
+// 当计数器不存在时执行，用于从数据源获取计数器的初始值
+// key: 计数器的键
+// 返回: 初始值, 错误信息
+type CounterFallbackFunc func(ctx context.Context, key string) (int64, error)
+
+// UpdateFunc 更新函数类型
+// 在Update读取到当前值后执行，直接修改Update的dst指向的值，修改后的值会被写回缓存
+// found: 键是否存在，不存在时dst为零值
+// 返回: 错误信息，返回错误时放弃本次更新
+type UpdateFunc func(ctx context.Context, found bool) error
+
+// TTLFunc 过期时间函数类型
+// 为每个回退结果单独决定过期时间，如按实体的更新频率区分或加入随机抖动
+// key: 键名
+// value: 回退函数返回的值
+// 返回: 过期时间，0表示永不过期
+type TTLFunc func(key string, value interface{}) time.Duration
+
 // CacheOptions 缓存选项
 type CacheOptions struct {
 	// TTL 缓存过期时间，0表示永不过期
 	TTL time.Duration
+
+	// TTLFunc 为每个回退结果单独计算过期时间，设置后写入回退结果时代替TTL
+	// 各键的TTL不同且Store实现store.EntrySetter时一次写入，否则按TTL分组写入；
+	// 滑动过期的续期仍使用TTL
+	TTLFunc TTLFunc
+
+	// Sliding 启用滑动过期，每次Get/MGet命中都会将过期时间重置为TTL
+	// 需要底层Store实现store.Expirer，否则读取时返回ErrNotSupported
+	Sliding bool
+
+	// TouchInterval 滑动过期下两次续期的最小间隔，避免热点键每次读取都产生一次写入
+	// 0表示每次命中都续期
+	TouchInterval time.Duration
+
+	// MaxLifetime 滑动过期下键的最长存活时间，到期后不再续期，0表示不限制
+	// 存活起点在本进程内记录：由本进程写入的键从写入时算起，其他键从首次命中时算起
+	MaxLifetime time.Duration
 }
 
 // Cacher 高级缓存接口，提供带回退机制的缓存操作
@@ -34,11 +73,12 @@ type Cacher interface {
 	Get(ctx context.Context, key string, dst interface{}, fallback FallbackFunc, opts *CacheOptions) (bool, error)
 
 	// MGet 批量获取缓存项，支持部分命中和批量回退
+	// Store无法读取(如无法解码)的键按未命中处理，交给回退函数并以回退结果覆盖
 	// keys: 要获取的键列表
 	// dstMap: 目标map的指针，用于接收结果，类型为*map[string]T
 	// fallback: 批量回退函数，处理未命中的键
 	// opts: 缓存选项，可以为nil使用默认选项
-	// 返回: 错误信息
+	// 返回: 错误信息，无法读取且回退后仍缺失的键以*store.BatchError返回(可用errors.As检查)，此时dstMap中其余键仍然有效
 	MGet(ctx context.Context, keys []string, dstMap interface{}, fallback BatchFallbackFunc, opts *CacheOptions) error
 
 	// MDelete 批量清除缓存项
@@ -53,4 +93,72 @@ type Cacher interface {
 	// opts: 缓存选项，可以为nil使用默认选项
 	// 返回: 错误信息
 	MRefresh(ctx context.Context, keys []string, dstMap interface{}, fallback BatchFallbackFunc, opts *CacheOptions) error
+
+	// Scan 遍历匹配pattern的键，底层Store需要实现store.Scanner
+	// pattern: Redis风格的glob模式，空字符串匹配所有键
+	// 返回: 键的迭代器，出错或Store不支持时以非nil错误作为最后一个元素，
+	// 不支持时错误满足errors.Is(err, ErrNotSupported)
+	Scan(ctx context.Context, pattern string) iter.Seq2[string, error]
+
+	// TTL 批量读取键的剩余过期时间，底层Store需要实现store.Expirer
+	// 返回: 键到剩余时间的映射(永不过期为store.NoTTL，不存在的键不包含在内), 错误信息
+	TTL(ctx context.Context, keys []string) (map[string]time.Duration, error)
+
+	// Expire 为已存在的键设置新的过期时间而不重写值，底层Store需要实现store.Expirer
+	// 返回: 实际更新的键数量, 错误信息
+	Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error)
+
+	// Persist 移除键的过期时间，底层Store需要实现store.Expirer
+	// 返回: 被移除过期时间的键数量, 错误信息
+	Persist(ctx context.Context, keys []string) (int64, error)
+
+	// OnEvict 注册缓存项离开缓存(容量淘汰、过期、删除或被覆盖)时的回调，底层Store需要实现store.EvictNotifier
+	// 能够报告的原因和回调的执行方式取决于Store；命名空间的Cacher只收到本命名空间的事件，键不含前缀
+	// 返回: 不支持时返回满足errors.Is(err, ErrNotSupported)的错误
+	OnEvict(fn func(store.Event)) error
+
+	// Watch 订阅匹配pattern的键的变化(写入、删除和过期)，底层Store需要实现store.Watcher
+	// ctx被取消时结束订阅并关闭channel；处理过慢时事件会被丢弃，见store.WatchEvent.Missed
+	// 命名空间的Cacher只收到本命名空间的事件，键不含前缀
+	// 返回: 事件channel, 错误信息(不支持时满足errors.Is(err, ErrNotSupported))
+	Watch(ctx context.Context, pattern string) (<-chan store.WatchEvent, error)
+
+	// Incr 原子地增加计数器，底层Store需要实现store.Counter
+	// key: 计数器的键
+	// delta: 增量，负数表示减少
+	// fallback: 计数器不存在时获取初始值的回退函数，为nil时从0开始计数
+	// opts: 缓存选项，TTL只在计数器没有过期时间时设置
+	// 返回: 增加后的值, 错误信息
+	Incr(ctx context.Context, key string, delta int64, fallback CounterFallbackFunc, opts *CacheOptions) (int64, error)
+
+	// Update 以比较并交换的方式读-改-写单个缓存项，底层Store需要实现store.CAS
+	// 并发写入导致版本冲突时重新读取并再次执行fn，多次冲突后返回ErrConflict。
+	// 内存Store返回的是缓存值本身，fn中不要原地修改map或切片，应替换为新的map或切片
+	// key: 键名
+	// dst: 目标变量的指针，读取当前值后交给fn修改
+	// fn: 更新函数，可能被执行多次
+	// opts: 缓存选项，可以为nil使用默认选项
+	// 返回: 错误信息
+	Update(ctx context.Context, key string, dst interface{}, fn UpdateFunc, opts *CacheOptions) error
+
+	// Namespace 返回命名空间的Cacher，其中所有键都以"命名空间:代数:"为前缀
+	// 代数保存在根Store的"cacher:namespace:gen:命名空间"键中，并在本地短暂缓存，根Cacher不应使用以"cacher:namespace:"开头的键
+	// namespace: 命名空间名
+	// 返回: 命名空间的Cacher，同一命名空间总是返回同一个实例
+	Namespace(namespace string) Cacher
+
+	// FlushNamespace 清空命名空间，底层Store需要实现store.Counter
+	// 递增命名空间的代数，旧代数下的键不再可达并随各自的TTL过期，不需要遍历删除；
+	// Store实现store.PubSub时通知其他进程立即丢弃本地缓存的代数
+	// 返回: 错误信息
+	FlushNamespace(ctx context.Context, namespace string) error
+
+	// Ping 检查底层Store是否可用，Store没有实现store.Pinger(如内存缓存)时视为可用
+	// 返回: 错误信息
+	Ping(ctx context.Context) error
+
+	// Close 停止后台任务并关闭底层Store(如果实现了store.Closer)
+	// 命名空间Cacher与根Cacher共享Store，其Close不做任何事，由根Cacher负责关闭
+	// 返回: 错误信息
+	Close() error
 }
@@ -3,7 +3,9 @@ package cacher
 import (
 	"context"
 	"fmt"
+	"iter"
 	"reflect"
+	"sync"
 	"time"
 
 	"go-cache/cacher/store"
@@ -11,26 +13,74 @@ import (
 
 // CacherImpl Cacher接口的实现
 type CacherImpl struct {
-	store store.Store
+	store   store.Store
+	sliding *slidingTracker
+	seeds   *seedGroup
+
+	// namespaces 根Cacher与其命名空间共享的命名空间状态
+	namespaces *namespaceRegistry
+
+	opts Options
+}
+
+// Options Cacher配置
+type Options struct {
+	// GenerationTTL 本地缓存命名空间代数的时间，默认1秒
+	// Store支持store.PubSub时，其他进程清空命名空间会立即使本地缓存失效，
+	// 否则最多在该时间后读到新的代数
+	GenerationTTL time.Duration
+
+	// BatchSize MGet/MRefresh中单次Store调用最多包含的键数量，超出时分批执行后合并，0表示不拆分
+	BatchSize int
+
+	// FallbackBatchSize MGet/MRefresh中单次批量回退函数调用最多包含的键数量，
+	// 超出时分批调用后合并结果，0表示不拆分
+	FallbackBatchSize int
+
+	// Parallelism 拆分后同时执行的批次数量，0使用默认值4
+	Parallelism int
 }
 
 // NewCacher 创建新的Cacher实例
 func NewCacher(store store.Store) Cacher {
+	return NewCacherWithOptions(store, nil)
+}
+
+// NewCacherWithOptions 使用指定配置创建Cacher实例
+// opts: 配置项，可以为nil使用默认配置
+func NewCacherWithOptions(store store.Store, opts *Options) Cacher {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = defaultParallelism
+	}
+
 	return &CacherImpl{
-		store: store,
+		store:      store,
+		sliding:    newSlidingTracker(),
+		seeds:      newSeedGroup(),
+		namespaces: newNamespaceRegistry(store, o.GenerationTTL),
+		opts:       o,
 	}
 }
 
 // Get 获取单个缓存项，缓存未命中时执行回退函数并缓存结果
 func (c *CacherImpl) Get(ctx context.Context, key string, dst interface{}, fallback FallbackFunc, opts *CacheOptions) (bool, error) {
+	if err := c.checkSliding(opts); err != nil {
+		return false, err
+	}
+
 	// 首先尝试从缓存获取
 	found, err := c.store.Get(ctx, key, dst)
 	if err != nil {
 		return false, fmt.Errorf("failed to get from store: %w", err)
 	}
 
-	// 如果缓存命中，直接返回
+	// 如果缓存命中，滑动过期下续期后返回
 	if found {
+		c.touch(ctx, []string{key}, opts)
 		return true, nil
 	}
 
@@ -54,12 +104,13 @@ func (c *CacherImpl) Get(ctx context.Context, key string, dst interface{}, fallb
 	}
 
 	// 缓存fallback的结果
-	ttl := c.getTTL(opts)
 	items := map[string]interface{}{key: value}
-	if err := c.store.MSet(ctx, items, ttl); err != nil {
+	if written, err := c.fill(ctx, items, opts); err != nil {
 		// 记录错误但不影响返回结果
 		// 在实际生产环境中，这里应该使用日志系统
 		_ = fmt.Errorf("failed to cache value: %w", err)
+	} else {
+		c.written(written, opts)
 	}
 
 	return true, nil
@@ -70,6 +121,9 @@ func (c *CacherImpl) MGet(ctx context.Context, keys []string, dstMap interface{}
 	if len(keys) == 0 {
 		return nil
 	}
+	if err := c.checkSliding(opts); err != nil {
+		return err
+	}
 
 	// 验证dstMap是map指针
 	dstMapValue := reflect.ValueOf(dstMap)
@@ -85,8 +139,8 @@ func (c *CacherImpl) MGet(ctx context.Context, keys []string, dstMap interface{}
 		mapValue.Set(reflect.MakeMap(mapType))
 	}
 
-	// 首先尝试从缓存批量获取
-	err := c.store.MGet(ctx, keys, dstMap)
+	// 首先尝试从缓存批量获取，无法读取的键按未命中处理
+	batchErr, err := c.mgetStore(ctx, keys, dstMap)
 	if err != nil {
 		return fmt.Errorf("failed to mget from store: %w", err)
 	}
@@ -94,15 +148,21 @@ func (c *CacherImpl) MGet(ctx context.Context, keys []string, dstMap interface{}
 	// 检查哪些键未命中缓存
 	currentMap := mapValue.Interface()
 	missedKeys := make([]string, 0)
+	hitKeys := make([]string, 0, len(keys))
 	
 	currentMapValue := reflect.ValueOf(currentMap)
 	for _, key := range keys {
 		keyValue := reflect.ValueOf(key)
 		if !currentMapValue.MapIndex(keyValue).IsValid() {
 			missedKeys = append(missedKeys, key)
+		} else {
+			hitKeys = append(hitKeys, key)
 		}
 	}
 
+	// 滑动过期下为命中的键续期
+	c.touch(ctx, hitKeys, opts)
+
 	// 如果所有键都命中缓存，直接返回
 	if len(missedKeys) == 0 {
 		return nil
@@ -110,7 +170,7 @@ func (c *CacherImpl) MGet(ctx context.Context, keys []string, dstMap interface{}
 
 	// 如果有未命中的键且有fallback函数，调用fallback
 	if fallback != nil {
-		fallbackResults, err := fallback(ctx, missedKeys)
+		fallbackResults, err := c.callFallback(ctx, missedKeys, fallback)
 		if err != nil {
 			return fmt.Errorf("batch fallback error: %w", err)
 		}
@@ -131,15 +191,19 @@ func (c *CacherImpl) MGet(ctx context.Context, keys []string, dstMap interface{}
 			mapValue.SetMapIndex(keyValue, valuePtr.Elem())
 		}
 
+		// 无法读取的键由回退结果替换，先删除以免MSetNX跳过这些键
+		c.dropUnreadable(ctx, fallbackResults, batchErr)
+
 		// 缓存fallback的结果
-		ttl := c.getTTL(opts)
-		if err := c.store.MSet(ctx, fallbackResults, ttl); err != nil {
+		if written, err := c.fill(ctx, fallbackResults, opts); err != nil {
 			// 记录错误但不影响返回结果
 			_ = fmt.Errorf("failed to cache fallback values: %w", err)
+		} else {
+			c.written(written, opts)
 		}
 	}
 
-	return nil
+	return c.unresolved(mapValue, batchErr)
 }
 
 // MDelete 批量清除缓存项
@@ -152,6 +216,7 @@ func (c *CacherImpl) MDelete(ctx context.Context, keys []string) (int64, error)
 	if err != nil {
 		return 0, fmt.Errorf("failed to delete from store: %w", err)
 	}
+	c.forget(keys)
 
 	return deletedCount, nil
 }
@@ -182,7 +247,7 @@ func (c *CacherImpl) MRefresh(ctx context.Context, keys []string, dstMap interfa
 	}
 
 	// 调用fallback获取最新数据
-	fallbackResults, err := fallback(ctx, keys)
+	fallbackResults, err := c.callFallback(ctx, keys, fallback)
 	if err != nil {
 		return fmt.Errorf("batch fallback error for refresh: %w", err)
 	}
@@ -203,11 +268,143 @@ func (c *CacherImpl) MRefresh(ctx context.Context, keys []string, dstMap interfa
 	}
 
 	// 更新缓存
-	ttl := c.getTTL(opts)
-	if err := c.store.MSet(ctx, fallbackResults, ttl); err != nil {
+	if err := c.refresh(ctx, fallbackResults, opts); err != nil {
 		return fmt.Errorf("failed to refresh cache: %w", err)
 	}
+	c.written(mapKeys(fallbackResults), opts)
+
+	return nil
+}
+
+// Scan 遍历匹配pattern的键
+func (c *CacherImpl) Scan(ctx context.Context, pattern string) iter.Seq2[string, error] {
+	scanner, ok := store.As[store.Scanner](c.store)
+	if !ok {
+		return func(yield func(string, error) bool) {
+			yield("", &NotSupportedError{Operation: "Scan"})
+		}
+	}
+
+	return func(yield func(string, error) bool) {
+		for key, err := range scanner.Scan(ctx, pattern, 0, 0) {
+			if err != nil {
+				yield("", fmt.Errorf("failed to scan store: %w", err))
+				return
+			}
+			if !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
+
+// TTL 批量读取键的剩余过期时间
+func (c *CacherImpl) TTL(ctx context.Context, keys []string) (map[string]time.Duration, error) {
+	expirer, err := c.expirer("TTL")
+	if err != nil {
+		return nil, err
+	}
+
+	ttls, err := expirer.TTL(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ttl from store: %w", err)
+	}
+
+	return ttls, nil
+}
 
+// Expire 为已存在的键设置新的过期时间
+func (c *CacherImpl) Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error) {
+	expirer, err := c.expirer("Expire")
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := expirer.Expire(ctx, keys, ttl)
+	if err != nil {
+		return count, fmt.Errorf("failed to expire keys in store: %w", err)
+	}
+
+	return count, nil
+}
+
+// Persist 移除键的过期时间
+func (c *CacherImpl) Persist(ctx context.Context, keys []string) (int64, error) {
+	expirer, err := c.expirer("Persist")
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := expirer.Persist(ctx, keys)
+	if err != nil {
+		return count, fmt.Errorf("failed to persist keys in store: %w", err)
+	}
+
+	return count, nil
+}
+
+// OnEvict 注册缓存项离开缓存时的回调
+func (c *CacherImpl) OnEvict(fn func(store.Event)) error {
+	notifier, ok := store.As[store.EvictNotifier](c.store)
+	if !ok {
+		return &NotSupportedError{Operation: "OnEvict"}
+	}
+	notifier.OnEvict(fn)
+	return nil
+}
+
+// Watch 订阅匹配pattern的键的变化
+func (c *CacherImpl) Watch(ctx context.Context, pattern string) (<-chan store.WatchEvent, error) {
+	watcher, ok := store.As[store.Watcher](c.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "Watch"}
+	}
+
+	events, err := watcher.Watch(ctx, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch store: %w", err)
+	}
+	return events, nil
+}
+
+// expirer 返回底层Store的Expirer实现，不支持时返回NotSupportedError
+func (c *CacherImpl) expirer(operation string) (store.Expirer, error) {
+	expirer, ok := store.As[store.Expirer](c.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: operation}
+	}
+	return expirer, nil
+}
+
+// Ping 检查底层Store是否可用
+func (c *CacherImpl) Ping(ctx context.Context) error {
+	pinger, ok := store.As[store.Pinger](c.store)
+	if !ok {
+		return nil
+	}
+
+	if err := pinger.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping store: %w", err)
+	}
+	return nil
+}
+
+// Close 停止命名空间失效消息的订阅并关闭底层Store
+func (c *CacherImpl) Close() error {
+	// 命名空间Cacher不负责关闭共享的Store
+	if _, ok := c.store.(*namespaceStore); ok {
+		return nil
+	}
+
+	c.namespaces.close()
+
+	closer, ok := store.As[store.Closer](c.store)
+	if !ok {
+		return nil
+	}
+	if err := closer.Close(); err != nil {
+		return fmt.Errorf("failed to close store: %w", err)
+	}
 	return nil
 }
 
@@ -246,5 +443,195 @@ func (c *CacherImpl) getTTL(opts *CacheOptions) time.Duration {
 	return opts.TTL
 }
 
+// ttlGroups 按写入时使用的TTL对回退结果分组，未设置TTLFunc时只有一组
+func (c *CacherImpl) ttlGroups(items map[string]interface{}, opts *CacheOptions) map[time.Duration]map[string]interface{} {
+	groups := make(map[time.Duration]map[string]interface{})
+	if len(items) == 0 {
+		return groups
+	}
+
+	if opts == nil || opts.TTLFunc == nil {
+		groups[c.writeTTL(opts)] = items
+		return groups
+	}
+
+	for key, value := range items {
+		ttl := c.limitTTL(opts.TTLFunc(key, value), opts)
+		group, ok := groups[ttl]
+		if !ok {
+			group = make(map[string]interface{})
+			groups[ttl] = group
+		}
+		group[key] = value
+	}
+	return groups
+}
+
+// setEntries 各键的TTL不同且Store实现store.EntrySetter时一次写入所有分组
+// 返回: 是否已写入, 错误信息
+func (c *CacherImpl) setEntries(ctx context.Context, groups map[time.Duration]map[string]interface{}) (bool, error) {
+	if len(groups) < 2 {
+		return false, nil
+	}
+	setter, ok := store.As[store.EntrySetter](c.store)
+	if !ok {
+		return false, nil
+	}
+
+	return true, setter.MSetEntries(ctx, groupEntries(groups))
+}
+
+// groupEntries 将按TTL分组的键值对展开为Entry
+func groupEntries(groups map[time.Duration]map[string]interface{}) []store.Entry {
+	var entries []store.Entry
+	for ttl, group := range groups {
+		for key, value := range group {
+			entries = append(entries, store.Entry{Key: key, Value: value, TTL: ttl})
+		}
+	}
+	return entries
+}
+
+// fill 缓存回退函数的结果，键超过BatchSize时分批并发写入
+// Store支持store.ConditionalSetter时使用MSetNX或MSetEntriesNX，不覆盖回退期间其他调用方写入的更新的值；
+// 否则各键的TTL不同时优先使用MSetEntries一次写入
+// 返回: 实际写入的键, 错误信息
+func (c *CacherImpl) fill(ctx context.Context, items map[string]interface{}, opts *CacheOptions) ([]string, error) {
+	var (
+		mutex   sync.Mutex
+		written []string
+	)
+	err := c.forEachItemChunk(ctx, items, func(ctx context.Context, chunk map[string]interface{}) error {
+		keys, err := c.fillChunk(ctx, chunk, opts)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		written = append(written, keys...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// fillChunk 缓存一批回退函数的结果
+// 支持条件写入时整批只执行一次条件写入：各键TTL相同时使用MSetNX，否则使用MSetEntriesNX
+func (c *CacherImpl) fillChunk(ctx context.Context, items map[string]interface{}, opts *CacheOptions) ([]string, error) {
+	groups := c.ttlGroups(items, opts)
+
+	setter, conditional := store.As[store.ConditionalSetter](c.store)
+	if !conditional {
+		if set, err := c.setEntries(ctx, groups); set || err != nil {
+			if err != nil {
+				return nil, err
+			}
+			return mapKeys(items), nil
+		}
+
+		for ttl, group := range groups {
+			if err := c.store.MSet(ctx, group, ttl); err != nil {
+				return nil, err
+			}
+		}
+		return mapKeys(items), nil
+	}
+
+	var written map[string]bool
+	var err error
+	if len(groups) < 2 {
+		for ttl, group := range groups {
+			written, err = setter.MSetNX(ctx, group, ttl)
+		}
+	} else {
+		written, err = setter.MSetEntriesNX(ctx, groupEntries(groups))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(written))
+	for key, ok := range written {
+		if ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// refresh 覆盖写入刷新得到的回退结果，键超过BatchSize时分批并发写入
+func (c *CacherImpl) refresh(ctx context.Context, items map[string]interface{}, opts *CacheOptions) error {
+	return c.forEachItemChunk(ctx, items, func(ctx context.Context, chunk map[string]interface{}) error {
+		return c.refreshChunk(ctx, chunk, opts)
+	})
+}
+
+// refreshChunk 覆盖写入一批回退结果
+func (c *CacherImpl) refreshChunk(ctx context.Context, items map[string]interface{}, opts *CacheOptions) error {
+	groups := c.ttlGroups(items, opts)
+	if set, err := c.setEntries(ctx, groups); set || err != nil {
+		return err
+	}
+
+	for ttl, group := range groups {
+		if err := c.store.MSet(ctx, group, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropUnreadable 删除回退函数返回了新值的无法读取的键
+func (c *CacherImpl) dropUnreadable(ctx context.Context, items map[string]interface{}, batchErr *store.BatchError) {
+	if batchErr == nil {
+		return
+	}
+
+	keys := make([]string, 0, len(batchErr.Errors))
+	for key := range batchErr.Errors {
+		if _, ok := items[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	if _, err := c.store.Del(ctx, keys...); err != nil {
+		// 记录错误但不影响返回结果
+		_ = fmt.Errorf("failed to delete unreadable keys: %w", err)
+	}
+}
+
+// unresolved 返回回退后仍不在结果中的无法读取的键，都已解决时返回nil
+func (c *CacherImpl) unresolved(mapValue reflect.Value, batchErr *store.BatchError) error {
+	if batchErr == nil {
+		return nil
+	}
+
+	remaining := &store.BatchError{}
+	for key, err := range batchErr.Errors {
+		if !mapValue.MapIndex(reflect.ValueOf(key)).IsValid() {
+			remaining.Add(key, err)
+		}
+	}
+	if err := remaining.Err(); err != nil {
+		return fmt.Errorf("failed to mget from store: %w", err)
+	}
+	return nil
+}
+
+// mapKeys 返回map的所有键
+func mapKeys(items map[string]interface{}) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // 确保CacherImpl实现了Cacher接口
 var _ Cacher = (*CacherImpl)(nil)
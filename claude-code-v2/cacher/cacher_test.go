@@ -3,11 +3,16 @@ package cacher
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+	"go-cache/cacher/store/ristretto"
 )
 
 // MockStore 模拟Store实现，用于测试
@@ -437,3 +442,522 @@ func TestCacherWithMockStore(t *testing.T) {
 	assert.True(t, found)
 	assert.Equal(t, "value", result)
 }
+
+// TestCacherScan 测试Scan方法
+func TestCacherScan(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"user:1": 1, "user:2": 2, "order:1": 1}, 0))
+
+	var keys []string
+	for key, err := range c.Scan(ctx, "user:*") {
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+
+	// 不支持Scanner的Store返回ErrNotSupported
+	var scanErr error
+	for _, err := range NewCacher(NewMockStore()).Scan(ctx, "*") {
+		scanErr = err
+	}
+	assert.ErrorIs(t, scanErr, ErrNotSupported)
+	var notSupported *NotSupportedError
+	require.ErrorAs(t, scanErr, &notSupported)
+	assert.Equal(t, "Scan", notSupported.Operation)
+}
+
+// TestCacherExpirer 测试TTL、Expire和Persist方法
+func TestCacherExpirer(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"k": "v"}, time.Hour))
+
+	count, err := c.Expire(ctx, []string{"k"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	ttls, err := c.TTL(ctx, []string{"k"})
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Minute), float64(ttls["k"]), float64(time.Second))
+
+	count, err = c.Persist(ctx, []string{"k"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// 不支持Expirer的Store返回ErrNotSupported
+	mockCacher := NewCacher(NewMockStore())
+	_, err = mockCacher.TTL(ctx, []string{"k"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+	_, err = mockCacher.Expire(ctx, []string{"k"}, time.Minute)
+	assert.ErrorIs(t, err, ErrNotSupported)
+	_, err = mockCacher.Persist(ctx, []string{"k"})
+	var notSupported *NotSupportedError
+	require.ErrorAs(t, err, &notSupported)
+	assert.Equal(t, "Persist", notSupported.Operation)
+}
+
+// TestCacherIncr 测试Incr方法
+func TestCacherIncr(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+
+	// 并发调用时回退函数只执行一次
+	var fallbackCalls atomic.Int32
+	fallback := func(ctx context.Context, key string) (int64, error) {
+		fallbackCalls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return 100, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Incr(ctx, "views", 1, fallback, &CacheOptions{TTL: time.Hour})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), fallbackCalls.Load())
+
+	value, err := c.Incr(ctx, "views", -10, fallback, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), value)
+
+	// 没有回退函数时从0开始
+	value, err = c.Incr(ctx, "fresh", 3, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+
+	// 回退函数出错时不写入计数器
+	_, err = c.Incr(ctx, "broken", 1, func(ctx context.Context, key string) (int64, error) {
+		return 0, errors.New("db down")
+	}, nil)
+	assert.Error(t, err)
+	exists, err := ristrettoStore.Exists(ctx, []string{"broken"})
+	require.NoError(t, err)
+	assert.False(t, exists["broken"])
+
+	// 不支持Counter的Store返回ErrNotSupported
+	_, err = NewCacher(NewMockStore()).Incr(ctx, "views", 1, nil, nil)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+// TestCacherUpdate 测试Update方法
+func TestCacherUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+
+	type account struct {
+		Balance int
+		Updates int
+	}
+
+	// 并发更新不丢失写入
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				var acc account
+				err := c.Update(ctx, "account", &acc, func(ctx context.Context, found bool) error {
+					acc.Balance += 10
+					acc.Updates++
+					return nil
+				}, nil)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var acc account
+	found, err := ristrettoStore.Get(ctx, "account", &acc)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, account{Balance: 800, Updates: 80}, acc)
+
+	// 更新函数出错时不写入
+	abort := errors.New("insufficient balance")
+	err = c.Update(ctx, "account", &acc, func(ctx context.Context, found bool) error {
+		return abort
+	}, nil)
+	assert.ErrorIs(t, err, abort)
+
+	// 不存在的键以零值交给更新函数
+	var created account
+	err = c.Update(ctx, "new", &created, func(ctx context.Context, found bool) error {
+		assert.False(t, found)
+		created.Balance = 1
+		return nil
+	}, &CacheOptions{TTL: time.Hour})
+	require.NoError(t, err)
+
+	// 不支持CAS的Store返回ErrNotSupported
+	err = NewCacher(NewMockStore()).Update(ctx, "account", &acc, func(ctx context.Context, found bool) error {
+		return nil
+	}, nil)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+// conflictingStore 每次SetIfVersion都报告版本冲突
+type conflictingStore struct {
+	*ristretto.Store
+}
+
+func (s *conflictingStore) SetIfVersion(ctx context.Context, key string, value interface{}, version store.Version, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+// TestCacherUpdateConflict 测试多次冲突后返回ErrConflict
+func TestCacherUpdateConflict(t *testing.T) {
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(&conflictingStore{Store: ristrettoStore})
+
+	calls := 0
+	var value string
+	err = c.Update(context.Background(), "k", &value, func(ctx context.Context, found bool) error {
+		calls++
+		return nil
+	}, nil)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, maxUpdateAttempts, calls)
+}
+
+// TestCacherFallbackSetNX 测试回退结果不覆盖回退期间写入的值
+func TestCacherFallbackSetNX(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+
+	// 回退期间其他调用方写入了更新的值
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{key: "newer"}, 0))
+		return "stale", true, nil
+	}
+
+	var result string
+	found, err := c.Get(ctx, "k", &result, fallback, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "stale", result)
+
+	_, err = ristrettoStore.Get(ctx, "k", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "newer", result)
+
+	// MGet同样不覆盖
+	batchFallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"a": "newer"}, 0))
+		return map[string]interface{}{"a": "stale", "b": "fresh"}, nil
+	}
+	values := make(map[string]string)
+	require.NoError(t, c.MGet(ctx, []string{"a", "b"}, &values, batchFallback, nil))
+
+	stored := make(map[string]string)
+	require.NoError(t, ristrettoStore.MGet(ctx, []string{"a", "b"}, &stored))
+	assert.Equal(t, map[string]string{"a": "newer", "b": "fresh"}, stored)
+
+	// MRefresh强制覆盖
+	require.NoError(t, c.MRefresh(ctx, []string{"a"}, &values, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"a": "refreshed"}, nil
+	}, nil))
+	_, err = ristrettoStore.Get(ctx, "a", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed", result)
+}
+
+// TestCacherTTLFunc 测试按键计算回退结果的过期时间
+func TestCacherTTLFunc(t *testing.T) {
+	ctx := context.Background()
+
+	ttlFunc := func(key string, value interface{}) time.Duration {
+		if value.(string) == "hot" {
+			return time.Minute
+		}
+		return time.Hour
+	}
+	batchFallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"a": "hot", "b": "cold"}, nil
+	}
+
+	// 支持条件写入的Store按TTL分组使用MSetNX
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+	values := make(map[string]string)
+	require.NoError(t, c.MGet(ctx, []string{"a", "b"}, &values, batchFallback, &CacheOptions{TTL: time.Second, TTLFunc: ttlFunc}))
+
+	ttls, err := ristrettoStore.TTL(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttls["a"], float64(time.Second))
+	assert.InDelta(t, time.Hour, ttls["b"], float64(time.Second))
+
+	// 单个键的回退同样使用TTLFunc
+	var result string
+	_, err = c.Get(ctx, "c", &result, func(ctx context.Context, key string) (interface{}, bool, error) {
+		return "hot", true, nil
+	}, &CacheOptions{TTLFunc: ttlFunc})
+	require.NoError(t, err)
+	ttls, err = ristrettoStore.TTL(ctx, []string{"c"})
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttls["c"], float64(time.Second))
+
+	// 不支持EntrySetter的Store按TTL分组写入
+	mockStore := NewMockStore()
+	c = NewCacher(mockStore)
+	values = make(map[string]string)
+	require.NoError(t, c.MRefresh(ctx, []string{"a", "b"}, &values, batchFallback, &CacheOptions{TTLFunc: ttlFunc}))
+	assert.Equal(t, map[string]string{"a": "hot", "b": "cold"}, values)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), mockStore.ttls["a"], time.Second)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), mockStore.ttls["b"], time.Second)
+}
+
+// TestCacherTTLFuncNoClobber 测试各键TTL不同时写入回退结果不覆盖回退期间写入的值
+func TestCacherTTLFuncNoClobber(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	ttlFunc := func(key string, value interface{}) time.Duration {
+		if key == "a" {
+			return time.Minute
+		}
+		return time.Hour
+	}
+	batchFallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		// 模拟回退期间其他调用方写入了更新的值
+		require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"a": "newer"}, 0))
+		return map[string]interface{}{"a": "stale", "b": "cold"}, nil
+	}
+
+	recording := &batchRecordingStore{Store: ristrettoStore}
+	c := NewCacher(recording)
+	values := make(map[string]string)
+	require.NoError(t, c.MGet(ctx, []string{"a", "b"}, &values, batchFallback, &CacheOptions{TTLFunc: ttlFunc}))
+
+	// 各键TTL不同时仍只执行一次条件写入
+	assert.Equal(t, []int{2}, recording.msetSizes)
+
+	var result string
+	found, err := ristrettoStore.Get(ctx, "a", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "newer", result)
+
+	ttls, err := ristrettoStore.TTL(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, store.NoTTL, ttls["a"])
+	assert.InDelta(t, time.Hour, ttls["b"], float64(time.Second))
+}
+
+// TestCacherMGetUnreadable 测试无法读取的键按未命中处理
+func TestCacherMGetUnreadable(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	// "b"以字符串写入，读取为int时失败
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"a": 1, "b": "two"}, 0))
+
+	c := NewCacher(ristrettoStore)
+
+	// 没有回退函数时返回其余键，并可以用errors.As检查失败的键
+	values := make(map[string]int)
+	err = c.MGet(ctx, []string{"a", "b"}, &values, nil, nil)
+	var batchErr *store.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []string{"b"}, batchErr.Keys())
+	assert.Equal(t, map[string]int{"a": 1}, values)
+
+	// 回退函数返回的值替换无法读取的键
+	fallbackKeys := []string(nil)
+	fallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		fallbackKeys = keys
+		return map[string]interface{}{"b": 2}, nil
+	}
+	values = make(map[string]int)
+	require.NoError(t, c.MGet(ctx, []string{"a", "b"}, &values, fallback, nil))
+	assert.Equal(t, []string{"b"}, fallbackKeys)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, values)
+
+	var result int
+	found, err := ristrettoStore.Get(ctx, "b", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 2, result)
+}
+
+// batchRecordingStore 记录每次MGet和条件写入的键数量的Ristretto Store
+type batchRecordingStore struct {
+	*ristretto.Store
+	mutex     sync.Mutex
+	mgetSizes []int
+	msetSizes []int
+}
+
+func (s *batchRecordingStore) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	s.mutex.Lock()
+	s.mgetSizes = append(s.mgetSizes, len(keys))
+	s.mutex.Unlock()
+	return s.Store.MGet(ctx, keys, dstMap)
+}
+
+func (s *batchRecordingStore) MSetNX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	s.mutex.Lock()
+	s.msetSizes = append(s.msetSizes, len(items))
+	s.mutex.Unlock()
+	return s.Store.MSetNX(ctx, items, ttl)
+}
+
+func (s *batchRecordingStore) MSetEntriesNX(ctx context.Context, entries []store.Entry) (map[string]bool, error) {
+	s.mutex.Lock()
+	s.msetSizes = append(s.msetSizes, len(entries))
+	s.mutex.Unlock()
+	return s.Store.MSetEntriesNX(ctx, entries)
+}
+
+// TestCacherBatching 测试超大批量的拆分
+func TestCacherBatching(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+	recording := &batchRecordingStore{Store: ristrettoStore}
+
+	c := NewCacherWithOptions(recording, &Options{BatchSize: 3, FallbackBatchSize: 4, Parallelism: 2})
+
+	keys := make([]string, 10)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i)
+	}
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"k0": 0, "k1": 1}, 0))
+
+	var (
+		mutex         sync.Mutex
+		fallbackSizes []int
+		inFlight      atomic.Int32
+		maxInFlight   atomic.Int32
+	)
+	fallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			current := maxInFlight.Load()
+			if n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		fallbackSizes = append(fallbackSizes, len(keys))
+		mutex.Unlock()
+
+		results := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			var i int
+			fmt.Sscanf(key, "k%d", &i)
+			results[key] = i
+		}
+		return results, nil
+	}
+
+	values := make(map[string]int)
+	require.NoError(t, c.MGet(ctx, keys, &values, fallback, nil))
+	assert.Len(t, values, len(keys))
+	assert.Equal(t, 9, values["k9"])
+
+	// 10个键分4批读取，8个未命中的键分2批回退，分3批写入
+	assert.ElementsMatch(t, []int{3, 3, 3, 1}, recording.mgetSizes)
+	assert.ElementsMatch(t, []int{4, 4}, fallbackSizes)
+	assert.ElementsMatch(t, []int{3, 3, 2}, recording.msetSizes)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+
+	// MRefresh同样分批回退
+	fallbackSizes = nil
+	values = make(map[string]int)
+	require.NoError(t, c.MRefresh(ctx, keys, &values, fallback, nil))
+	assert.Len(t, values, len(keys))
+	assert.ElementsMatch(t, []int{4, 4, 2}, fallbackSizes)
+
+	// 任一批回退失败时整个调用失败
+	errFallback := errors.New("fallback failed")
+	err = c.MRefresh(ctx, keys, &values, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return nil, errFallback
+	}, nil)
+	assert.ErrorIs(t, err, errFallback)
+}
+
+// closeCountingStore 统计Close调用的Ristretto Store
+type closeCountingStore struct {
+	*ristretto.Store
+	closes int
+}
+
+func (s *closeCountingStore) Close() error {
+	s.closes++
+	return s.Store.Close()
+}
+
+// TestCacherLifecycle 测试Ping和Close方法
+func TestCacherLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	s := &closeCountingStore{Store: ristrettoStore}
+
+	c := NewCacher(s)
+	assert.Equal(t, store.CapCloser|store.CapFlusher, store.Capabilities(s)&(store.CapCloser|store.CapFlusher|store.CapPinger))
+
+	// 没有实现Pinger的Store视为可用
+	assert.NoError(t, c.Ping(ctx))
+
+	// 命名空间Cacher不关闭共享的Store
+	require.NoError(t, c.Namespace("product").Close())
+	assert.Zero(t, s.closes)
+
+	require.NoError(t, c.Close())
+	assert.Equal(t, 1, s.closes)
+
+	// 不支持Closer的Store关闭时不做任何事
+	assert.NoError(t, NewCacher(NewMockStore()).Close())
+}
@@ -0,0 +1,118 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// seedCall 正在进行的一次计数器初始化
+type seedCall struct {
+	done chan struct{}
+	err  error
+}
+
+// seedGroup 合并同一计数器的并发初始化，保证本进程内回退函数只执行一次
+type seedGroup struct {
+	mutex sync.Mutex
+	calls map[string]*seedCall
+}
+
+func newSeedGroup() *seedGroup {
+	return &seedGroup{calls: make(map[string]*seedCall)}
+}
+
+// do 执行fn，同一键的并发调用等待首个调用完成并共享其结果
+func (g *seedGroup) do(ctx context.Context, key string, fn func() error) error {
+	g.mutex.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mutex.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	call := &seedCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	call.err = fn()
+
+	g.mutex.Lock()
+	delete(g.calls, key)
+	g.mutex.Unlock()
+	close(call.done)
+
+	return call.err
+}
+
+// Incr 原子地增加计数器，计数器不存在时先用回退函数的结果初始化
+func (c *CacherImpl) Incr(ctx context.Context, key string, delta int64, fallback CounterFallbackFunc, opts *CacheOptions) (int64, error) {
+	counter, ok := store.As[store.Counter](c.store)
+	if !ok {
+		return 0, &NotSupportedError{Operation: "Incr"}
+	}
+
+	ttl := c.getTTL(opts)
+
+	if fallback != nil {
+		if err := c.seedCounter(ctx, counter, key, fallback, ttl); err != nil {
+			return 0, err
+		}
+	}
+
+	value, err := counter.IncrBy(ctx, key, delta, ttl)
+	if err != nil {
+		return 0, fmt.Errorf("failed to incr counter in store: %w", err)
+	}
+
+	return value, nil
+}
+
+// seedCounter 计数器不存在时执行回退函数并写入初始值
+// 回退函数只在本进程内去重；Store实现store.ConditionalSetter时初始值只会写入一次，
+// 否则多个进程同时初始化同一计数器时初始值可能被重复累加
+func (c *CacherImpl) seedCounter(ctx context.Context, counter store.Counter, key string, fallback CounterFallbackFunc, ttl time.Duration) error {
+	exists, err := c.store.Exists(ctx, []string{key})
+	if err != nil {
+		return fmt.Errorf("failed to check counter in store: %w", err)
+	}
+	if exists[key] {
+		return nil
+	}
+
+	return c.seeds.do(ctx, key, func() error {
+		// 检查之后可能已被其他调用初始化
+		exists, err := c.store.Exists(ctx, []string{key})
+		if err != nil {
+			return fmt.Errorf("failed to check counter in store: %w", err)
+		}
+		if exists[key] {
+			return nil
+		}
+
+		seed, err := fallback(ctx, key)
+		if err != nil {
+			return fmt.Errorf("fallback function error: %w", err)
+		}
+
+		// 支持MSetNX时以不存在为条件写入初始值，多个进程同时初始化时只有一个初始值生效
+		if setter, ok := store.As[store.ConditionalSetter](c.store); ok {
+			if _, err := setter.MSetNX(ctx, map[string]interface{}{key: seed}, ttl); err != nil {
+				return fmt.Errorf("failed to seed counter in store: %w", err)
+			}
+			return nil
+		}
+
+		if _, err := counter.IncrBy(ctx, key, seed, ttl); err != nil {
+			return fmt.Errorf("failed to seed counter in store: %w", err)
+		}
+		return nil
+	})
+}
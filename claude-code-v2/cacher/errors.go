@@ -0,0 +1,28 @@
+package cacher
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotSupported 底层Store没有实现所需的可选接口
+// 可以用errors.Is(err, ErrNotSupported)判断
+var ErrNotSupported = errors.New("operation not supported by store")
+
+// NotSupportedError 底层Store不支持某项操作时返回的错误
+type NotSupportedError struct {
+	// Operation 不支持的操作名，如"Scan"
+	Operation string
+}
+
+func (e *NotSupportedError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Operation, ErrNotSupported)
+}
+
+// Is 使errors.Is(err, ErrNotSupported)成立
+func (e *NotSupportedError) Is(target error) bool {
+	return target == ErrNotSupported
+}
+
+// ErrConflict Update多次重试后仍因并发写入而版本冲突
+var ErrConflict = errors.New("update conflict: too many concurrent writes")
@@ -0,0 +1,246 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// namespaceChannel 广播命名空间清空消息的channel，消息内容为命名空间名
+const namespaceChannel = "cacher:namespace:flush"
+
+// defaultGenerationTTL 本地缓存命名空间代数的默认时间
+const defaultGenerationTTL = time.Second
+
+// namespaceEscaper 转义命名空间名中的':'，使"命名空间:代数:键"可以无歧义地拆分
+var namespaceEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+// escapeNamespace 返回用于键前缀的命名空间名，结果不含':'
+func escapeNamespace(namespace string) string {
+	return namespaceEscaper.Replace(namespace)
+}
+
+// generationKeyPrefix 代数键的保留前缀，根Cacher不应使用以此开头的键
+// 命名空间中的键以"转义后的命名空间:代数:"开头，代数只含数字，不会产生以此开头的键
+const generationKeyPrefix = "cacher:namespace:gen:"
+
+// generationKey 返回保存命名空间代数的键
+func generationKey(namespace string) string {
+	return generationKeyPrefix + escapeNamespace(namespace)
+}
+
+// generationEntry 本地缓存的命名空间代数
+type generationEntry struct {
+	generation int64
+	expiresAt  time.Time
+}
+
+// namespaceRegistry 根Cacher及其所有命名空间共享的状态
+type namespaceRegistry struct {
+	// store 根Cacher的Store，代数键保存在其中
+	store store.Store
+	ttl   time.Duration
+
+	mutex       sync.Mutex
+	generations map[string]generationEntry
+	cachers     map[string]*CacherImpl
+
+	subscribeOnce sync.Once
+	// cancel 取消失效消息的订阅
+	cancel context.CancelFunc
+}
+
+func newNamespaceRegistry(s store.Store, ttl time.Duration) *namespaceRegistry {
+	if ttl <= 0 {
+		ttl = defaultGenerationTTL
+	}
+	return &namespaceRegistry{
+		store:       s,
+		ttl:         ttl,
+		generations: make(map[string]generationEntry),
+		cachers:     make(map[string]*CacherImpl),
+	}
+}
+
+// Namespace 返回指定命名空间的Cacher，同一命名空间总是返回同一个实例
+// 命名空间不嵌套，在命名空间Cacher上调用等同于在根Cacher上调用
+func (c *CacherImpl) Namespace(namespace string) Cacher {
+	r := c.namespaces
+	r.subscribe()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if cacher, ok := r.cachers[namespace]; ok {
+		return cacher
+	}
+
+	cacher := &CacherImpl{
+		store: &namespaceStore{
+			store:     r.store,
+			namespace: namespace,
+			escaped:   escapeNamespace(namespace),
+			registry:  r,
+		},
+		sliding:    newSlidingTracker(),
+		seeds:      newSeedGroup(),
+		namespaces: r,
+		opts:       c.opts,
+	}
+	r.cachers[namespace] = cacher
+	return cacher
+}
+
+// FlushNamespace 递增命名空间的代数，旧代数下的键不再可达并随TTL过期
+func (c *CacherImpl) FlushNamespace(ctx context.Context, namespace string) error {
+	r := c.namespaces
+
+	counter, ok := store.As[store.Counter](r.store)
+	if !ok {
+		return &NotSupportedError{Operation: "FlushNamespace"}
+	}
+
+	// 先确保代数键存在，否则IncrBy从0开始，可能回到被淘汰前用过的代数
+	if _, err := r.load(ctx, namespace); err != nil {
+		return err
+	}
+	generation, err := counter.IncrBy(ctx, generationKey(namespace), 1, 0)
+	if err != nil {
+		return fmt.Errorf("failed to bump namespace generation: %w", err)
+	}
+	r.set(namespace, generation)
+
+	// 通知其他进程丢弃本地缓存的代数，通知失败时其他进程在本地缓存过期后读到新代数
+	if pubsub, ok := store.As[store.PubSub](r.store); ok {
+		if err := pubsub.Publish(ctx, namespaceChannel, namespace); err != nil {
+			// 记录错误但不影响返回结果
+			_ = fmt.Errorf("failed to publish namespace flush: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// subscribe 首次使用命名空间时订阅失效消息，Store不支持PubSub时只依赖本地缓存过期
+func (r *namespaceRegistry) subscribe() {
+	r.subscribeOnce.Do(func() {
+		pubsub, ok := store.As[store.PubSub](r.store)
+		if !ok {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := pubsub.Subscribe(ctx, namespaceChannel, r.invalidate); err != nil {
+			// 订阅失败时只依赖本地缓存过期
+			cancel()
+			return
+		}
+
+		r.mutex.Lock()
+		r.cancel = cancel
+		r.mutex.Unlock()
+	})
+}
+
+// close 取消失效消息的订阅
+func (r *namespaceRegistry) close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// generation 返回命名空间的当前代数，优先使用本地缓存
+func (r *namespaceRegistry) generation(ctx context.Context, namespace string) (int64, error) {
+	r.mutex.Lock()
+	entry, ok := r.generations[namespace]
+	r.mutex.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.generation, nil
+	}
+
+	generation, err := r.load(ctx, namespace)
+	if err != nil {
+		return 0, err
+	}
+	r.set(namespace, generation)
+	return generation, nil
+}
+
+// load 从Store读取命名空间的代数，代数键不存在时写入初始代数
+// 代数键可能被淘汰，初始代数取当前时间的纳秒数而不是0，避免回到已清空的代数使旧数据重新可达
+func (r *namespaceRegistry) load(ctx context.Context, namespace string) (int64, error) {
+	key := generationKey(namespace)
+
+	var generation int64
+	found, err := r.store.Get(ctx, key, &generation)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get namespace generation: %w", err)
+	}
+	if found {
+		return generation, nil
+	}
+
+	seed := time.Now().UnixNano()
+	setter, ok := store.As[store.ConditionalSetter](r.store)
+	if !ok {
+		// 无法条件写入时直接写入，并发初始化的进程在本地缓存过期后读到同一代数
+		if err := r.store.MSet(ctx, map[string]interface{}{key: seed}, 0); err != nil {
+			return 0, fmt.Errorf("failed to seed namespace generation: %w", err)
+		}
+		return seed, nil
+	}
+
+	written, err := setter.MSetNX(ctx, map[string]interface{}{key: seed}, 0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to seed namespace generation: %w", err)
+	}
+	if written[key] {
+		return seed, nil
+	}
+
+	// 其他进程已写入初始代数，读取其结果
+	if _, err := r.store.Get(ctx, key, &generation); err != nil {
+		return 0, fmt.Errorf("failed to get namespace generation: %w", err)
+	}
+	return generation, nil
+}
+
+// set 更新本地缓存的代数
+func (r *namespaceRegistry) set(namespace string, generation int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.generations[namespace] = generationEntry{
+		generation: generation,
+		expiresAt:  time.Now().Add(r.ttl),
+	}
+}
+
+// invalidate 丢弃本地缓存的代数
+func (r *namespaceRegistry) invalidate(namespace string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.generations, namespace)
+}
+
+// escapePattern 转义glob模式中的特殊字符
+func escapePattern(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,418 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// namespaceStore 为所有键加上"命名空间:代数:"前缀的Store包装，前缀中的命名空间名经过escapeNamespace转义
+// 实现了键相关的可选接口，被包装的Store不支持时返回NotSupportedError；
+// 不实现Closer和Flusher，被包装的Store由根Cacher负责关闭
+type namespaceStore struct {
+	store     store.Store
+	namespace string
+	// escaped 转义后的命名空间名，用于键前缀
+	escaped  string
+	registry *namespaceRegistry
+}
+
+// prefix 返回当前代数下的键前缀
+func (s *namespaceStore) prefix(ctx context.Context) (string, error) {
+	generation, err := s.registry.generation(ctx, s.namespace)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d:", s.escaped, generation), nil
+}
+
+// prefixKeys 为键列表加上前缀
+func prefixKeys(prefix string, keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = prefix + key
+	}
+	return prefixed
+}
+
+// prefixItems 为map的键加上前缀
+func prefixItems[V any](prefix string, items map[string]V) map[string]V {
+	prefixed := make(map[string]V, len(items))
+	for key, value := range items {
+		prefixed[prefix+key] = value
+	}
+	return prefixed
+}
+
+// trimItems 去掉map的键的前缀
+func trimItems[V any](prefix string, items map[string]V) map[string]V {
+	trimmed := make(map[string]V, len(items))
+	for key, value := range items {
+		trimmed[strings.TrimPrefix(key, prefix)] = value
+	}
+	return trimmed
+}
+
+// Unwrap 返回被包装的Store
+func (s *namespaceStore) Unwrap() store.Store {
+	return s.store
+}
+
+// Ping 检查被包装的Store
+func (s *namespaceStore) Ping(ctx context.Context) error {
+	pinger, ok := store.As[store.Pinger](s.store)
+	if !ok {
+		return &NotSupportedError{Operation: "Ping"}
+	}
+	return pinger.Ping(ctx)
+}
+
+func (s *namespaceStore) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return false, err
+	}
+	return s.store.Get(ctx, prefix+key, dst)
+}
+
+func (s *namespaceStore) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	dstMapValue := reflect.ValueOf(dstMap)
+	if dstMapValue.Kind() != reflect.Ptr || dstMapValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("dstMap must be a pointer to map")
+	}
+
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 读取到同类型的临时map，再去掉前缀写入dstMap
+	mapValue := dstMapValue.Elem()
+	prefixed := reflect.New(mapValue.Type())
+	prefixed.Elem().Set(reflect.MakeMap(mapValue.Type()))
+	// 部分键失败时其余键仍然有效，失败的键同样去掉前缀
+	var batchErr *store.BatchError
+	if err := s.store.MGet(ctx, prefixKeys(prefix, keys), prefixed.Interface()); err != nil {
+		if !errors.As(err, &batchErr) {
+			return err
+		}
+		batchErr = &store.BatchError{Errors: trimItems(prefix, batchErr.Errors)}
+	}
+
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapValue.Type()))
+	}
+	entries := prefixed.Elem().MapRange()
+	for entries.Next() {
+		key := strings.TrimPrefix(entries.Key().String(), prefix)
+		mapValue.SetMapIndex(reflect.ValueOf(key), entries.Value())
+	}
+
+	return batchErr.Err()
+}
+
+func (s *namespaceStore) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.store.Exists(ctx, prefixKeys(prefix, keys))
+	if err != nil {
+		return nil, err
+	}
+	return trimItems(prefix, exists), nil
+}
+
+func (s *namespaceStore) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	return s.store.MSet(ctx, prefixItems(prefix, items), ttl)
+}
+
+func (s *namespaceStore) Del(ctx context.Context, keys ...string) (int64, error) {
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return s.store.Del(ctx, prefixKeys(prefix, keys)...)
+}
+
+func (s *namespaceStore) Scan(ctx context.Context, pattern string, cursor uint64, count int64) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		scanner, ok := store.As[store.Scanner](s.store)
+		if !ok {
+			yield("", &NotSupportedError{Operation: "Scan"})
+			return
+		}
+
+		prefix, err := s.prefix(ctx)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		if pattern == "" {
+			pattern = "*"
+		}
+		for key, err := range scanner.Scan(ctx, escapePattern(prefix)+pattern, cursor, count) {
+			if !yield(strings.TrimPrefix(key, prefix), err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *namespaceStore) TTL(ctx context.Context, keys []string) (map[string]time.Duration, error) {
+	expirer, ok := store.As[store.Expirer](s.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "TTL"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ttls, err := expirer.TTL(ctx, prefixKeys(prefix, keys))
+	if err != nil {
+		return nil, err
+	}
+	return trimItems(prefix, ttls), nil
+}
+
+func (s *namespaceStore) Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error) {
+	expirer, ok := store.As[store.Expirer](s.store)
+	if !ok {
+		return 0, &NotSupportedError{Operation: "Expire"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return expirer.Expire(ctx, prefixKeys(prefix, keys), ttl)
+}
+
+func (s *namespaceStore) Persist(ctx context.Context, keys []string) (int64, error) {
+	expirer, ok := store.As[store.Expirer](s.store)
+	if !ok {
+		return 0, &NotSupportedError{Operation: "Persist"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return expirer.Persist(ctx, prefixKeys(prefix, keys))
+}
+
+func (s *namespaceStore) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	counter, ok := store.As[store.Counter](s.store)
+	if !ok {
+		return 0, &NotSupportedError{Operation: "IncrBy"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return counter.IncrBy(ctx, prefix+key, delta, ttl)
+}
+
+func (s *namespaceStore) MIncrBy(ctx context.Context, deltas map[string]int64, ttl time.Duration) (map[string]int64, error) {
+	counter, ok := store.As[store.Counter](s.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "MIncrBy"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values, err := counter.MIncrBy(ctx, prefixItems(prefix, deltas), ttl)
+	if err != nil {
+		return nil, err
+	}
+	return trimItems(prefix, values), nil
+}
+
+func (s *namespaceStore) GetVersioned(ctx context.Context, key string, dst interface{}) (store.Version, bool, error) {
+	cas, ok := store.As[store.CAS](s.store)
+	if !ok {
+		return store.NoVersion, false, &NotSupportedError{Operation: "GetVersioned"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return store.NoVersion, false, err
+	}
+	return cas.GetVersioned(ctx, prefix+key, dst)
+}
+
+func (s *namespaceStore) SetIfVersion(ctx context.Context, key string, value interface{}, version store.Version, ttl time.Duration) (bool, error) {
+	cas, ok := store.As[store.CAS](s.store)
+	if !ok {
+		return false, &NotSupportedError{Operation: "SetIfVersion"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return false, err
+	}
+	return cas.SetIfVersion(ctx, prefix+key, value, version, ttl)
+}
+
+func (s *namespaceStore) MSetNX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	setter, ok := store.As[store.ConditionalSetter](s.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "MSetNX"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	written, err := setter.MSetNX(ctx, prefixItems(prefix, items), ttl)
+	if err != nil {
+		return nil, err
+	}
+	return trimItems(prefix, written), nil
+}
+
+func (s *namespaceStore) MSetXX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	setter, ok := store.As[store.ConditionalSetter](s.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "MSetXX"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	written, err := setter.MSetXX(ctx, prefixItems(prefix, items), ttl)
+	if err != nil {
+		return nil, err
+	}
+	return trimItems(prefix, written), nil
+}
+
+func (s *namespaceStore) MSetEntriesNX(ctx context.Context, entries []store.Entry) (map[string]bool, error) {
+	setter, ok := store.As[store.ConditionalSetter](s.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "MSetEntriesNX"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefixed := make([]store.Entry, len(entries))
+	for i, entry := range entries {
+		entry.Key = prefix + entry.Key
+		prefixed[i] = entry
+	}
+	written, err := setter.MSetEntriesNX(ctx, prefixed)
+	if err != nil {
+		return nil, err
+	}
+	return trimItems(prefix, written), nil
+}
+
+func (s *namespaceStore) MSetEntries(ctx context.Context, entries []store.Entry) error {
+	setter, ok := store.As[store.EntrySetter](s.store)
+	if !ok {
+		return &NotSupportedError{Operation: "MSetEntries"}
+	}
+	prefix, err := s.prefix(ctx)
+	if err != nil {
+		return err
+	}
+	prefixed := make([]store.Entry, len(entries))
+	for i, entry := range entries {
+		entry.Key = prefix + entry.Key
+		prefixed[i] = entry
+	}
+	return setter.MSetEntries(ctx, prefixed)
+}
+
+// trimKey 去掉被包装的Store中键的"命名空间:代数:"前缀，不属于本命名空间(任意代数)的键返回false
+func (s *namespaceStore) trimKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, s.escaped+":")
+	if !ok {
+		return "", false
+	}
+	generation, key, ok := strings.Cut(rest, ":")
+	if !ok || generation == "" || strings.Trim(generation, "0123456789") != "" {
+		return "", false
+	}
+	return key, true
+}
+
+// OnEvict 在被包装的Store上注册回调，只转发本命名空间(任意代数)的键并去掉前缀
+// 被包装的Store不支持EvictNotifier时不做任何事
+func (s *namespaceStore) OnEvict(fn func(store.Event)) {
+	notifier, ok := store.As[store.EvictNotifier](s.store)
+	if !ok {
+		return
+	}
+
+	notifier.OnEvict(func(event store.Event) {
+		key, ok := s.trimKey(event.Key)
+		if !ok {
+			return
+		}
+		event.Key = key
+		fn(event)
+	})
+}
+
+// Watch 订阅被包装的Store中本命名空间(任意代数)的键，按pattern过滤并去掉前缀
+func (s *namespaceStore) Watch(ctx context.Context, pattern string) (<-chan store.WatchEvent, error) {
+	watcher, ok := store.As[store.Watcher](s.store)
+	if !ok {
+		return nil, &NotSupportedError{Operation: "Watch"}
+	}
+
+	events, err := watcher.Watch(ctx, escapePattern(s.escaped)+":*")
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan store.WatchEvent, store.DefaultWatchBuffer)
+	go func() {
+		defer close(out)
+
+		// 被过滤掉的事件之前丢弃的数量累加到下一个转发的事件
+		var missed uint64
+		for event := range events {
+			key, ok := s.trimKey(event.Key)
+			if !ok || !store.MatchPattern(pattern, key) {
+				missed += event.Missed
+				continue
+			}
+			event.Key = key
+			event.Missed += missed
+			missed = 0
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// 确保namespaceStore实现了所有接口
+var (
+	_ store.Store             = (*namespaceStore)(nil)
+	_ store.Wrapper           = (*namespaceStore)(nil)
+	_ store.Pinger            = (*namespaceStore)(nil)
+	_ store.Scanner           = (*namespaceStore)(nil)
+	_ store.Expirer           = (*namespaceStore)(nil)
+	_ store.Counter           = (*namespaceStore)(nil)
+	_ store.CAS               = (*namespaceStore)(nil)
+	_ store.ConditionalSetter = (*namespaceStore)(nil)
+	_ store.EntrySetter       = (*namespaceStore)(nil)
+	_ store.EvictNotifier     = (*namespaceStore)(nil)
+	_ store.Watcher           = (*namespaceStore)(nil)
+)
@@ -0,0 +1,310 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+	"go-cache/cacher/store/redis"
+	"go-cache/cacher/store/ristretto"
+)
+
+func TestCacherNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+	products := c.Namespace("product")
+	assert.Same(t, products, c.Namespace("product"))
+
+	fallbackCalls := 0
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		fallbackCalls++
+		return "value", true, nil
+	}
+
+	var result string
+	_, err = products.Get(ctx, "1", &result, fallback, nil)
+	require.NoError(t, err)
+	_, err = products.Get(ctx, "1", &result, fallback, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fallbackCalls)
+
+	// 键以命名空间和代数为前缀
+	key := namespacePrefix(t, c, "product") + "1"
+	exists, err := ristrettoStore.Exists(ctx, []string{key})
+	require.NoError(t, err)
+	assert.True(t, exists[key])
+
+	// 其他命名空间不受影响
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{namespacePrefix(t, c, "order") + "1": "order"}, 0))
+	found, err := c.Namespace("order").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "order", result)
+
+	// 清空后旧键不可达
+	require.NoError(t, c.FlushNamespace(ctx, "product"))
+	_, err = products.Get(ctx, "1", &result, fallback, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fallbackCalls)
+
+	found, err = c.Namespace("order").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// MGet和Scan返回不带前缀的键
+	values := make(map[string]string)
+	require.NoError(t, products.MGet(ctx, []string{"1", "2"}, &values, func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"2": "two"}, nil
+	}, nil))
+	assert.Equal(t, map[string]string{"1": "value", "2": "two"}, values)
+
+	var keys []string
+	for key, err := range products.Scan(ctx, "*") {
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{"1", "2"}, keys)
+
+	// 命名空间内的计数器
+	value, err := products.Incr(ctx, "views", 1, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+}
+
+// namespacePrefix 返回命名空间当前代数下的键前缀
+func namespacePrefix(t *testing.T, c Cacher, namespace string) string {
+	prefix, err := c.Namespace(namespace).(*CacherImpl).store.(*namespaceStore).prefix(context.Background())
+	require.NoError(t, err)
+	return prefix
+}
+
+func TestCacherNamespaceKeys(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+
+	// 命名空间名中的':'被转义，不同命名空间的键不会重合
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{generationKey("a"): int64(1), generationKey("a:1"): int64(0)}, 0))
+	assert.Equal(t, "a:1:", namespacePrefix(t, c, "a"))
+	assert.Equal(t, "a%3A1:0:", namespacePrefix(t, c, "a:1"))
+	assert.Equal(t, "cacher:namespace:gen:a%3A1", generationKey("a:1"))
+
+	// 根Cacher的普通键不会与代数键冲突
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"b:gen": "user value"}, 0))
+	require.NoError(t, c.Namespace("b").(*CacherImpl).store.MSet(ctx, map[string]interface{}{"1": "v"}, 0))
+	require.NoError(t, c.FlushNamespace(ctx, "b"))
+	var rootValue string
+	found, err := c.Get(ctx, "b:gen", &rootValue, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "user value", rootValue)
+
+	// 代数键不存在时以当前时间为初始代数，被淘汰后不会回到已清空的代数
+	c = NewCacher(ristrettoStore)
+	before := namespacePrefix(t, c, "product")
+	assert.NotEqual(t, "product:0:", before)
+	require.NoError(t, c.FlushNamespace(ctx, "product"))
+	_, err = c.MDelete(ctx, []string{generationKey("product")})
+	require.NoError(t, err)
+	require.NoError(t, c.FlushNamespace(ctx, "product"))
+	after := namespacePrefix(t, c, "product")
+	assert.NotEqual(t, before, after)
+	assert.NotEqual(t, "product:1:", after)
+}
+
+func TestCacherNamespaceGenerationTTL(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	// 两个Cacher共享Store，Store不支持PubSub时依赖本地缓存过期
+	a := NewCacherWithOptions(ristrettoStore, &Options{GenerationTTL: 50 * time.Millisecond})
+	b := NewCacherWithOptions(ristrettoStore, &Options{GenerationTTL: 50 * time.Millisecond})
+
+	require.NoError(t, a.Namespace("product").(*CacherImpl).store.MSet(ctx, map[string]interface{}{"1": "v"}, 0))
+
+	var result string
+	found, err := b.Namespace("product").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	require.NoError(t, a.FlushNamespace(ctx, "product"))
+
+	// 本地缓存过期前仍使用旧代数
+	found, err = b.Namespace("product").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	time.Sleep(100 * time.Millisecond)
+	found, err = b.Namespace("product").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestCacherNamespacePubSub(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	// 模拟两个进程，各自使用独立的连接
+	clientA := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer clientA.Close()
+	clientB := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	defer clientB.Close()
+
+	a := NewCacherWithOptions(redis.NewStore(clientA), &Options{GenerationTTL: time.Hour})
+	b := NewCacherWithOptions(redis.NewStore(clientB), &Options{GenerationTTL: time.Hour})
+	defer a.(*CacherImpl).namespaces.close()
+	defer b.(*CacherImpl).namespaces.close()
+
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		return "v", true, nil
+	}
+	var result string
+	_, err = a.Namespace("product").Get(ctx, "1", &result, fallback, nil)
+	require.NoError(t, err)
+	found, err := b.Namespace("product").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	// 清空消息使其他进程立即丢弃本地缓存的代数
+	require.NoError(t, a.FlushNamespace(ctx, "product"))
+	assert.Eventually(t, func() bool {
+		found, err := b.Namespace("product").Get(ctx, "1", &result, nil, nil)
+		return err == nil && !found
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCacherNamespaceOnEvict(t *testing.T) {
+	ctx := context.Background()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+	var rootEvents, productEvents []store.Event
+	require.NoError(t, c.OnEvict(func(event store.Event) { rootEvents = append(rootEvents, event) }))
+	require.NoError(t, c.Namespace("product").OnEvict(func(event store.Event) { productEvents = append(productEvents, event) }))
+
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{
+		"product:0:1":            "a",
+		"order:0:1":              "b",
+		generationKey("product"): int64(0),
+		generationKey("order"):   int64(0),
+	}, 0))
+	_, err = c.Namespace("product").MDelete(ctx, []string{"1"})
+	require.NoError(t, err)
+	_, err = c.Namespace("order").MDelete(ctx, []string{"1"})
+	require.NoError(t, err)
+	_, err = c.MDelete(ctx, []string{generationKey("product")})
+	require.NoError(t, err)
+
+	// 根Cacher收到所有键，命名空间只收到本命名空间的键且不含前缀
+	assert.Len(t, rootEvents, 3)
+	assert.Equal(t, []store.Event{{Key: "1", Reason: store.EvictDeleted, Value: "a"}}, productEvents)
+
+	// Store不支持时返回ErrNotSupported
+	assert.ErrorIs(t, NewCacher(NewMockStore()).OnEvict(func(store.Event) {}), ErrNotSupported)
+}
+
+func TestCacherNamespaceWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	c := NewCacher(ristrettoStore)
+	events, err := c.Namespace("product").Watch(ctx, "1*")
+	require.NoError(t, err)
+
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"order:0:1": "a"}, 0))
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"product:0:2": "b"}, 0))
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"product:0:10": "c"}, 0))
+
+	// 代数递增后新代数的键同样可以收到
+	require.NoError(t, c.FlushNamespace(ctx, "product"))
+	_, err = c.Namespace("product").MDelete(ctx, []string{"1"})
+	require.NoError(t, err)
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"product:1:1": "d"}, 0))
+
+	assert.Equal(t, store.WatchEvent{Key: "10", Type: store.WatchSet}, <-events)
+	assert.Equal(t, store.WatchEvent{Key: "1", Type: store.WatchSet}, <-events)
+
+	cancel()
+	for range events {
+	}
+
+	// Store不支持时返回ErrNotSupported
+	_, err = NewCacher(NewMockStore()).Watch(context.Background(), "*")
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
+
+func TestCacherNamespaceNotSupported(t *testing.T) {
+	ctx := context.Background()
+	c := NewCacher(NewMockStore())
+
+	// 不支持Counter的Store不能清空命名空间
+	assert.ErrorIs(t, c.FlushNamespace(ctx, "product"), ErrNotSupported)
+
+	// 命名空间Cacher的能力取决于底层Store
+	_, err := c.Namespace("product").TTL(ctx, []string{"1"})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	var result string
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		return "v", true, nil
+	}
+	_, err = c.Namespace("product").Get(ctx, "1", &result, fallback, nil)
+	require.NoError(t, err)
+	found, err := c.Namespace("product").Get(ctx, "1", &result, nil, nil)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+// countingWrapper 包装Store并声明实现Counter，被包装的Store不支持时返回错误
+type countingWrapper struct {
+	store.Store
+}
+
+func (w countingWrapper) Unwrap() store.Store {
+	return w.Store
+}
+
+func (w countingWrapper) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	return 0, errors.New("inner store is not a counter")
+}
+
+func (w countingWrapper) MIncrBy(ctx context.Context, deltas map[string]int64, ttl time.Duration) (map[string]int64, error) {
+	return nil, errors.New("inner store is not a counter")
+}
+
+func TestCacherNamespaceWrappedStore(t *testing.T) {
+	ctx := context.Background()
+
+	// 能力取决于被包装的Store，而不是包装层声明的接口
+	c := NewCacher(countingWrapper{Store: NewMockStore()})
+	assert.ErrorIs(t, c.FlushNamespace(ctx, "product"), ErrNotSupported)
+	_, err := c.Namespace("product").Incr(ctx, "views", 1, nil, nil)
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
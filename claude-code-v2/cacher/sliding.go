@@ -0,0 +1,173 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// slidingPruneThreshold 记录数超过该值时清理不再需要的记录
+const slidingPruneThreshold = 1024
+
+// slidingEntry 单个键的滑动过期状态
+type slidingEntry struct {
+	// nextTouch 在此之前命中不再续期
+	nextTouch time.Time
+	// deadline 最长存活时间的截止时刻，零值表示不限制
+	deadline time.Time
+}
+
+// slidingTracker 记录滑动过期键的续期时间和存活截止时刻
+// 状态只保存在本进程内：其他进程写入或本进程重启前写入的键，以首次命中的时间作为存活起点
+type slidingTracker struct {
+	mutex   sync.Mutex
+	entries map[string]*slidingEntry
+
+	// pruneAt 记录数达到该值时触发清理，清理后按剩余数量翻倍，避免每次调用都遍历
+	pruneAt int
+}
+
+func newSlidingTracker() *slidingTracker {
+	return &slidingTracker{
+		entries: make(map[string]*slidingEntry),
+		pruneAt: slidingPruneThreshold,
+	}
+}
+
+// isSliding 判断选项是否启用了滑动过期
+func isSliding(opts *CacheOptions) bool {
+	return opts != nil && opts.Sliding && opts.TTL > 0
+}
+
+// checkSliding 启用滑动过期时检查Store是否支持续期
+func (c *CacherImpl) checkSliding(opts *CacheOptions) error {
+	if !isSliding(opts) {
+		return nil
+	}
+	if _, ok := store.As[store.Expirer](c.store); !ok {
+		return &NotSupportedError{Operation: "sliding expiration"}
+	}
+	return nil
+}
+
+// writeTTL 返回写入回退结果时使用的TTL，滑动过期下不超过最长存活时间
+func (c *CacherImpl) writeTTL(opts *CacheOptions) time.Duration {
+	return c.limitTTL(c.getTTL(opts), opts)
+}
+
+// limitTTL 滑动过期下将写入的TTL限制在最长存活时间内
+func (c *CacherImpl) limitTTL(ttl time.Duration, opts *CacheOptions) time.Duration {
+	if isSliding(opts) && opts.MaxLifetime > 0 && opts.MaxLifetime < ttl {
+		ttl = opts.MaxLifetime
+	}
+	return ttl
+}
+
+// written 记录键刚被写入，写入本身等同于一次续期
+func (c *CacherImpl) written(keys []string, opts *CacheOptions) {
+	if !isSliding(opts) {
+		return
+	}
+
+	now := time.Now()
+	t := c.sliding
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, key := range keys {
+		entry := &slidingEntry{nextTouch: now.Add(opts.TouchInterval)}
+		if opts.MaxLifetime > 0 {
+			entry.deadline = now.Add(opts.MaxLifetime)
+		}
+		t.entries[key] = entry
+	}
+	t.prune(now)
+}
+
+// forget 删除键的滑动过期状态
+func (c *CacherImpl) forget(keys []string) {
+	t := c.sliding
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, key := range keys {
+		delete(t.entries, key)
+	}
+}
+
+// touch 对命中的键续期，距上次续期不足TouchInterval的键会被跳过
+// 续期失败不影响读取结果，键会按原过期时间过期
+func (c *CacherImpl) touch(ctx context.Context, keys []string, opts *CacheOptions) {
+	if !isSliding(opts) || len(keys) == 0 {
+		return
+	}
+	expirer, ok := store.As[store.Expirer](c.store)
+	if !ok {
+		return
+	}
+
+	// 按续期时长分组，有最长存活时间时各键的剩余时长可能不同
+	groups := make(map[time.Duration][]string)
+	now := time.Now()
+
+	t := c.sliding
+	t.mutex.Lock()
+	for _, key := range keys {
+		entry, ok := t.entries[key]
+		if !ok {
+			entry = &slidingEntry{}
+			if opts.MaxLifetime > 0 {
+				entry.deadline = now.Add(opts.MaxLifetime)
+			}
+			t.entries[key] = entry
+		}
+		if now.Before(entry.nextTouch) {
+			continue
+		}
+
+		ttl := opts.TTL
+		if !entry.deadline.IsZero() {
+			remaining := entry.deadline.Sub(now)
+			if remaining <= 0 {
+				// 已达到最长存活时间，不再续期
+				continue
+			}
+			ttl = min(ttl, remaining)
+		}
+
+		entry.nextTouch = now.Add(opts.TouchInterval)
+		groups[ttl] = append(groups[ttl], key)
+	}
+	t.prune(now)
+	t.mutex.Unlock()
+
+	for ttl, group := range groups {
+		if _, err := expirer.Expire(ctx, group, ttl); err != nil {
+			// 记录错误但不影响返回结果
+			_ = fmt.Errorf("failed to touch keys: %w", err)
+		}
+	}
+}
+
+// prune 记录过多时清理已过续期间隔且没有存活截止时刻(或已过截止时刻)的记录
+// 调用方需要持有锁
+func (t *slidingTracker) prune(now time.Time) {
+	if len(t.entries) < t.pruneAt {
+		return
+	}
+	defer func() {
+		t.pruneAt = max(slidingPruneThreshold, len(t.entries)*2)
+	}()
+
+	for key, entry := range t.entries {
+		if now.Before(entry.nextTouch) {
+			continue
+		}
+		if entry.deadline.IsZero() || !now.Before(entry.deadline) {
+			delete(t.entries, key)
+		}
+	}
+}
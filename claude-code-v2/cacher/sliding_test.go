@@ -0,0 +1,153 @@
+package cacher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store/ristretto"
+)
+
+// touchCountingStore 统计Expire调用的Ristretto Store
+type touchCountingStore struct {
+	*ristretto.Store
+
+	mutex   sync.Mutex
+	touches map[string]int
+	ttls    []time.Duration
+}
+
+func newTouchCountingStore(t *testing.T) *touchCountingStore {
+	s, err := ristretto.NewStore()
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return &touchCountingStore{Store: s, touches: make(map[string]int)}
+}
+
+func (s *touchCountingStore) Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error) {
+	s.mutex.Lock()
+	for _, key := range keys {
+		s.touches[key]++
+	}
+	s.ttls = append(s.ttls, ttl)
+	s.mutex.Unlock()
+	return s.Store.Expire(ctx, keys, ttl)
+}
+
+func TestCacherSlidingExpiration(t *testing.T) {
+	ctx := context.Background()
+	s := newTouchCountingStore(t)
+	c := NewCacher(s)
+
+	opts := &CacheOptions{TTL: 150 * time.Millisecond, Sliding: true}
+	fallbackCalls := 0
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		fallbackCalls++
+		return "value", true, nil
+	}
+
+	var result string
+	_, err := c.Get(ctx, "session", &result, fallback, opts)
+	require.NoError(t, err)
+
+	// 持续访问时键不会过期
+	for i := 0; i < 5; i++ {
+		time.Sleep(60 * time.Millisecond)
+		found, err := c.Get(ctx, "session", &result, fallback, opts)
+		require.NoError(t, err)
+		assert.True(t, found)
+	}
+	assert.Equal(t, 1, fallbackCalls)
+	assert.Equal(t, 5, s.touches["session"])
+
+	// MGet命中同样续期，未命中的键不续期
+	values := make(map[string]string)
+	require.NoError(t, c.MGet(ctx, []string{"session", "missing"}, &values, nil, opts))
+	assert.Equal(t, 6, s.touches["session"])
+	assert.Zero(t, s.touches["missing"])
+
+	// 停止访问后按TTL过期
+	time.Sleep(250 * time.Millisecond)
+	_, err = c.Get(ctx, "session", &result, fallback, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fallbackCalls)
+}
+
+func TestCacherSlidingTouchInterval(t *testing.T) {
+	ctx := context.Background()
+	s := newTouchCountingStore(t)
+	c := NewCacher(s)
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"hot": "v"}, time.Minute))
+
+	opts := &CacheOptions{TTL: time.Minute, Sliding: true, TouchInterval: 100 * time.Millisecond}
+
+	// 间隔内的多次命中只续期一次
+	var result string
+	for i := 0; i < 10; i++ {
+		_, err := c.Get(ctx, "hot", &result, nil, opts)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, s.touches["hot"])
+
+	time.Sleep(120 * time.Millisecond)
+	_, err := c.Get(ctx, "hot", &result, nil, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.touches["hot"])
+
+	// 本进程写入的键写入时已视为续期
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		return "v", true, nil
+	}
+	_, err = c.Get(ctx, "fresh", &result, fallback, opts)
+	require.NoError(t, err)
+	_, err = c.Get(ctx, "fresh", &result, fallback, opts)
+	require.NoError(t, err)
+	assert.Zero(t, s.touches["fresh"])
+}
+
+func TestCacherSlidingMaxLifetime(t *testing.T) {
+	ctx := context.Background()
+	s := newTouchCountingStore(t)
+	c := NewCacher(s)
+
+	opts := &CacheOptions{TTL: 100 * time.Millisecond, Sliding: true, MaxLifetime: 250 * time.Millisecond}
+	fallbackCalls := 0
+	fallback := func(ctx context.Context, key string) (interface{}, bool, error) {
+		fallbackCalls++
+		return "value", true, nil
+	}
+
+	var result string
+	_, err := c.Get(ctx, "capped", &result, fallback, opts)
+	require.NoError(t, err)
+
+	// 即使持续访问，超过最长存活时间后也会过期
+	for i := 0; i < 8; i++ {
+		time.Sleep(50 * time.Millisecond)
+		_, err := c.Get(ctx, "capped", &result, fallback, opts)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, fallbackCalls)
+
+	// 续期时长不超过剩余存活时间
+	for _, ttl := range s.ttls {
+		assert.LessOrEqual(t, ttl, 100*time.Millisecond)
+	}
+	assert.Less(t, s.ttls[len(s.ttls)-1], 100*time.Millisecond)
+}
+
+func TestCacherSlidingNotSupported(t *testing.T) {
+	ctx := context.Background()
+	c := NewCacher(NewMockStore())
+
+	var result string
+	_, err := c.Get(ctx, "k", &result, nil, &CacheOptions{TTL: time.Minute, Sliding: true})
+	assert.ErrorIs(t, err, ErrNotSupported)
+
+	values := make(map[string]string)
+	err = c.MGet(ctx, []string{"k"}, &values, nil, &CacheOptions{TTL: time.Minute, Sliding: true})
+	assert.ErrorIs(t, err, ErrNotSupported)
+}
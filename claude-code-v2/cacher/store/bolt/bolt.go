@@ -0,0 +1,603 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"go-cache/cacher/store"
+	bolt "go.etcd.io/bbolt"
+	bolterrors "go.etcd.io/bbolt/errors"
+)
+
+var (
+	// dataBucket 存放键值记录
+	dataBucket = []byte("data")
+	// expiryBucket 过期索引，键为 过期时间(8字节大端)+数据键，便于按时间顺序清理
+	expiryBucket = []byte("expiry")
+)
+
+const (
+	// keyPrefix bbolt不允许空键，所有数据键统一加上前缀
+	keyPrefix = 'k'
+	// headerSize 记录头长度，存放过期时间(UnixNano，0表示永不过期)
+	headerSize = 8
+	// sweepBatchSize 每个事务最多清理的过期键数量，避免长事务阻塞写入
+	sweepBatchSize = 1000
+	// compactTxMaxSize 压缩时单个事务写入的最大字节数
+	compactTxMaxSize = 64 << 20
+)
+
+// Options Bolt Store配置
+type Options struct {
+	// Codec 值编解码器，为nil时使用store.DefaultCodec
+	Codec store.Codec
+
+	// SweepInterval 后台清理过期键的间隔，0使用默认值1分钟，负数表示禁用
+	SweepInterval time.Duration
+
+	// CompactInterval 后台压缩数据文件的间隔，0表示禁用
+	CompactInterval time.Duration
+
+	// Timeout 获取文件锁的超时时间，0使用默认值1秒
+	Timeout time.Duration
+
+	// FileMode 数据文件权限，0使用默认值0600
+	FileMode os.FileMode
+
+	// DeleteCorrupt 启用后MGet中无法解码的键在返回*store.BatchError的同时被删除
+	DeleteCorrupt bool
+}
+
+// ErrClosed Store已关闭，或压缩后无法重新打开数据文件
+var ErrClosed = errors.New("bolt store is closed")
+
+// openDB 打开bbolt文件，测试时可替换以模拟打开失败
+var openDB = bolt.Open
+
+// Store 基于bbolt文件的持久化Store实现
+type Store struct {
+	path  string
+	opts  Options
+	codec store.Codec
+
+	// mutex 保护db指针，压缩时需要替换底层文件
+	mutex sync.RWMutex
+	db    *bolt.DB
+	// closedErr 非nil时db不可用，所有操作返回该错误
+	closedErr error
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStore 打开(或创建)path处的bbolt文件并创建Store实例
+// opts: 配置项，可以为nil使用默认配置
+func NewStore(path string, opts *Options) (*Store, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+	if o.SweepInterval == 0 {
+		o.SweepInterval = time.Minute
+	}
+	if o.Timeout == 0 {
+		o.Timeout = time.Second
+	}
+	if o.FileMode == 0 {
+		o.FileMode = 0600
+	}
+
+	s := &Store{
+		path:  path,
+		opts:  o,
+		codec: o.Codec,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	db, err := s.open(path)
+	if err != nil {
+		return nil, err
+	}
+	s.db = db
+
+	go s.run()
+
+	return s, nil
+}
+
+// open 打开数据文件并确保所需的bucket存在
+func (s *Store) open(path string) (*bolt.DB, error) {
+	db, err := openDB(path, s.opts.FileMode, &bolt.Options{Timeout: s.opts.Timeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(dataBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(expiryBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create buckets: %w", err)
+	}
+
+	return db, nil
+}
+
+// Close 停止后台任务并关闭数据文件
+func (s *Store) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.closedErr == nil {
+			err = s.db.Close()
+		}
+		s.closedErr = ErrClosed
+	})
+	return err
+}
+
+// Flush 删除并重建数据和过期索引bucket
+func (s *Store) Flush(ctx context.Context) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closedErr != nil {
+		return s.closedErr
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{dataBucket, expiryBucket} {
+			if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolterrors.ErrBucketNotFound) {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("bolt flush error: %w", err)
+	}
+	return nil
+}
+
+// run 后台执行过期清理和压缩
+func (s *Store) run() {
+	defer close(s.done)
+
+	var sweepC, compactC <-chan time.Time
+	if s.opts.SweepInterval > 0 {
+		ticker := time.NewTicker(s.opts.SweepInterval)
+		defer ticker.Stop()
+		sweepC = ticker.C
+	}
+	if s.opts.CompactInterval > 0 {
+		ticker := time.NewTicker(s.opts.CompactInterval)
+		defer ticker.Stop()
+		compactC = ticker.C
+	}
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-sweepC:
+			// 后台任务出错时等待下一轮重试
+			_, _ = s.DeleteExpired()
+		case <-compactC:
+			_ = s.Compact()
+		}
+	}
+}
+
+// Get 从数据文件获取单个值
+func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closedErr != nil {
+		return false, s.closedErr
+	}
+
+	found := false
+	now := time.Now().UnixNano()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		record := tx.Bucket(dataBucket).Get(encodeKey(key))
+		if record == nil {
+			return nil
+		}
+
+		expiresAt, payload := decodeRecord(record)
+		if isExpired(expiresAt, now) {
+			return nil
+		}
+
+		if err := s.codec.Unmarshal(payload, dst); err != nil {
+			return fmt.Errorf("failed to decode value: %w", err)
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("bolt get error: %w", err)
+	}
+
+	return found, nil
+}
+
+// MGet 在单个读事务中批量获取值到map中
+func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 验证dstMap是map指针
+	dstMapValue := reflect.ValueOf(dstMap)
+	if dstMapValue.Kind() != reflect.Ptr || dstMapValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("dstMap must be a pointer to map")
+	}
+
+	mapValue := dstMapValue.Elem()
+	mapType := mapValue.Type()
+	valueType := mapType.Elem()
+
+	// 如果map为nil，初始化它
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapType))
+	}
+
+	// 无法解码的键跳过并记录，不影响其他键
+	batchErr := &store.BatchError{}
+
+	s.mutex.RLock()
+	if s.closedErr != nil {
+		s.mutex.RUnlock()
+		return s.closedErr
+	}
+	now := time.Now().UnixNano()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(dataBucket)
+		for _, key := range keys {
+			record := bucket.Get(encodeKey(key))
+			if record == nil {
+				continue
+			}
+
+			expiresAt, payload := decodeRecord(record)
+			if isExpired(expiresAt, now) {
+				continue
+			}
+
+			// 创建值类型的新实例并解码
+			valuePtr := reflect.New(valueType)
+			if err := s.codec.Unmarshal(payload, valuePtr.Interface()); err != nil {
+				batchErr.Add(key, fmt.Errorf("failed to decode value: %w", err))
+				continue
+			}
+
+			mapValue.SetMapIndex(reflect.ValueOf(key), valuePtr.Elem())
+		}
+		return nil
+	})
+	s.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// 读事务结束后才能删除
+	if s.opts.DeleteCorrupt && len(batchErr.Errors) > 0 {
+		// 删除失败不影响返回结果，下次读取时会再次尝试
+		_, _ = s.Del(ctx, batchErr.Keys()...)
+	}
+	return batchErr.Err()
+}
+
+// Exists 批量检查键存在性
+func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closedErr != nil {
+		return nil, s.closedErr
+	}
+
+	now := time.Now().UnixNano()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(dataBucket)
+		for _, key := range keys {
+			record := bucket.Get(encodeKey(key))
+			if record == nil {
+				result[key] = false
+				continue
+			}
+			expiresAt, _ := decodeRecord(record)
+			result[key] = !isExpired(expiresAt, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("bolt exists error: %w", err)
+	}
+
+	return result, nil
+}
+
+// MSet 在单个写事务中批量设置键值对，支持TTL
+func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	// 在事务外完成编码，缩短写事务的持有时间
+	records := make(map[string][]byte, len(items))
+	for key, value := range items {
+		payload, err := s.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value for key %s: %w", key, err)
+		}
+		records[key] = encodeRecord(expiresAt, payload)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closedErr != nil {
+		return s.closedErr
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(dataBucket)
+		index := tx.Bucket(expiryBucket)
+		for key, record := range records {
+			dataKey := encodeKey(key)
+			if err := removeIndex(bucket, index, dataKey); err != nil {
+				return err
+			}
+			if err := bucket.Put(dataKey, record); err != nil {
+				return err
+			}
+			if expiresAt != 0 {
+				if err := index.Put(encodeIndexKey(expiresAt, dataKey), nil); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("bolt mset error: %w", err)
+	}
+
+	return nil
+}
+
+// Del 删除指定键
+func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closedErr != nil {
+		return 0, s.closedErr
+	}
+
+	var deletedCount int64
+	now := time.Now().UnixNano()
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(dataBucket)
+		index := tx.Bucket(expiryBucket)
+		for _, key := range keys {
+			dataKey := encodeKey(key)
+			record := bucket.Get(dataKey)
+			if record == nil {
+				continue
+			}
+
+			// 已过期但尚未清理的键不计入删除数量
+			expiresAt, _ := decodeRecord(record)
+			if !isExpired(expiresAt, now) {
+				deletedCount++
+			}
+
+			if err := removeIndex(bucket, index, dataKey); err != nil {
+				return err
+			}
+			if err := bucket.Delete(dataKey); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("bolt del error: %w", err)
+	}
+
+	return deletedCount, nil
+}
+
+// DeleteExpired 清理所有已过期的键，返回清理的数量
+// 后台清理任务会定期调用此方法，也可以手动调用
+func (s *Store) DeleteExpired() (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closedErr != nil {
+		return 0, s.closedErr
+	}
+
+	total := 0
+	for {
+		now := time.Now().UnixNano()
+		deleted := 0
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket(dataBucket)
+			index := tx.Bucket(expiryBucket)
+
+			// 先收集再删除，避免边遍历边删除导致游标跳过元素
+			var indexKeys [][]byte
+			cursor := index.Cursor()
+			for k, _ := cursor.First(); k != nil && len(indexKeys) < sweepBatchSize; k, _ = cursor.Next() {
+				if int64(binary.BigEndian.Uint64(k[:headerSize])) > now {
+					break
+				}
+				indexKeys = append(indexKeys, append([]byte(nil), k...))
+			}
+
+			for _, k := range indexKeys {
+				dataKey := k[headerSize:]
+				if err := bucket.Delete(dataKey); err != nil {
+					return err
+				}
+				if err := index.Delete(k); err != nil {
+					return err
+				}
+			}
+			deleted = len(indexKeys)
+			return nil
+		})
+		if err != nil {
+			return total, fmt.Errorf("bolt sweep error: %w", err)
+		}
+
+		total += deleted
+		if deleted < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// Compact 将数据复制到新文件以回收已释放的空间，完成后替换原文件
+// 压缩期间所有读写操作会被阻塞
+func (s *Store) Compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closedErr != nil {
+		return s.closedErr
+	}
+
+	tmpPath := s.path + ".compact"
+	_ = os.Remove(tmpPath)
+
+	dst, err := bolt.Open(tmpPath, s.opts.FileMode, &bolt.Options{Timeout: s.opts.Timeout})
+	if err != nil {
+		return fmt.Errorf("failed to open compact target: %w", err)
+	}
+
+	if err := bolt.Compact(dst, s.db, compactTxMaxSize); err != nil {
+		dst.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to compact bolt db: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to close compact target: %w", err)
+	}
+
+	if err := s.db.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		s.closedErr = fmt.Errorf("%w: failed to close before compaction: %v", ErrClosed, err)
+		return s.closedErr
+	}
+
+	renameErr := os.Rename(tmpPath, s.path)
+
+	// 无论替换是否成功都需要重新打开，保证Store可用；
+	// 仍然失败时将Store标记为已关闭，之后的调用返回明确的错误而不是使用已关闭的db
+	db, err := s.open(s.path)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		s.closedErr = fmt.Errorf("%w: failed to reopen after compaction: %v", ErrClosed, err)
+		return s.closedErr
+	}
+	s.db = db
+
+	if renameErr != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace bolt db: %w", renameErr)
+	}
+
+	return nil
+}
+
+// removeIndex 删除数据键当前记录对应的过期索引
+func removeIndex(bucket, index *bolt.Bucket, dataKey []byte) error {
+	record := bucket.Get(dataKey)
+	if record == nil {
+		return nil
+	}
+	expiresAt, _ := decodeRecord(record)
+	if expiresAt == 0 {
+		return nil
+	}
+	return index.Delete(encodeIndexKey(expiresAt, dataKey))
+}
+
+// encodeKey 将业务键转换为bbolt数据键
+func encodeKey(key string) []byte {
+	dataKey := make([]byte, 0, len(key)+1)
+	dataKey = append(dataKey, keyPrefix)
+	return append(dataKey, key...)
+}
+
+// encodeIndexKey 生成过期索引键
+func encodeIndexKey(expiresAt int64, dataKey []byte) []byte {
+	indexKey := make([]byte, headerSize, headerSize+len(dataKey))
+	binary.BigEndian.PutUint64(indexKey, uint64(expiresAt))
+	return append(indexKey, dataKey...)
+}
+
+// encodeRecord 将过期时间和编码后的值组合为记录
+func encodeRecord(expiresAt int64, payload []byte) []byte {
+	record := make([]byte, headerSize, headerSize+len(payload))
+	binary.BigEndian.PutUint64(record, uint64(expiresAt))
+	return append(record, payload...)
+}
+
+// decodeRecord 拆分记录中的过期时间和值
+// 返回的payload仅在事务内有效
+func decodeRecord(record []byte) (int64, []byte) {
+	if len(record) < headerSize {
+		return 0, nil
+	}
+	return int64(binary.BigEndian.Uint64(record[:headerSize])), record[headerSize:]
+}
+
+// isExpired 检查过期时间是否已到
+func isExpired(expiresAt, now int64) bool {
+	return expiresAt != 0 && now >= expiresAt
+}
+
+// 确保Store实现了store.Store、store.Closer和store.Flusher接口
+var (
+	_ store.Store   = (*Store)(nil)
+	_ store.Closer  = (*Store)(nil)
+	_ store.Flusher = (*Store)(nil)
+)
@@ -0,0 +1,170 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltStore(t *testing.T) {
+	// 创建Bolt Store
+	boltStore, err := NewStore(filepath.Join(t.TempDir(), "cache.db"), nil)
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, boltStore)
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+}
+
+func TestBoltStorePersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.db")
+
+	boltStore, err := NewStore(path, nil)
+	require.NoError(t, err)
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"user:1": "alice"}, time.Hour))
+	require.NoError(t, boltStore.Close())
+
+	// 重新打开后数据仍然存在
+	boltStore, err = NewStore(path, nil)
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	var result string
+	found, err := boltStore.Get(ctx, "user:1", &result)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "alice", result)
+}
+
+func TestBoltStoreDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	boltStore, err := NewStore(filepath.Join(t.TempDir(), "cache.db"), &Options{SweepInterval: -1})
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"short1": 1, "short2": 2}, 50*time.Millisecond))
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"long": 3}, time.Hour))
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"forever": 4}, 0))
+
+	// 覆盖写入会替换旧的过期索引
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"short2": 5}, 0))
+
+	time.Sleep(100 * time.Millisecond)
+
+	deleted, err := boltStore.DeleteExpired()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	exists, err := boltStore.Exists(ctx, []string{"short1", "short2", "long", "forever"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"short1": false, "short2": true, "long": true, "forever": true}, exists)
+}
+
+func TestBoltStoreCompact(t *testing.T) {
+	ctx := context.Background()
+	boltStore, err := NewStore(filepath.Join(t.TempDir(), "cache.db"), &Options{SweepInterval: -1})
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	items := make(map[string]interface{})
+	for i := 0; i < 100; i++ {
+		items[fmt.Sprintf("compact_key_%d", i)] = i
+	}
+	require.NoError(t, boltStore.MSet(ctx, items, time.Hour))
+
+	require.NoError(t, boltStore.Compact())
+
+	// 压缩后数据和过期索引保持不变
+	resultMap := make(map[string]int)
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	require.NoError(t, boltStore.MGet(ctx, keys, &resultMap))
+	assert.Len(t, resultMap, len(items))
+}
+
+func TestBoltStoreCompactReopenFailure(t *testing.T) {
+	ctx := context.Background()
+	boltStore, err := NewStore(filepath.Join(t.TempDir(), "cache.db"), &Options{SweepInterval: -1})
+	require.NoError(t, err)
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"key": "value"}, 0))
+
+	// 压缩后重新打开数据文件失败
+	defer func(open func(string, os.FileMode, *bolt.Options) (*bolt.DB, error)) { openDB = open }(openDB)
+	openDB = func(string, os.FileMode, *bolt.Options) (*bolt.DB, error) {
+		return nil, errors.New("disk unavailable")
+	}
+
+	err = boltStore.Compact()
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorContains(t, err, "disk unavailable")
+
+	// 之后的调用返回明确的错误，而不是使用已关闭的db
+	var result string
+	_, err = boltStore.Get(ctx, "key", &result)
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, boltStore.MSet(ctx, map[string]interface{}{"key": "value"}, 0), ErrClosed)
+	assert.ErrorIs(t, boltStore.MGet(ctx, []string{"key"}, &map[string]string{}), ErrClosed)
+	assert.ErrorIs(t, boltStore.Compact(), ErrClosed)
+	assert.NoError(t, boltStore.Close())
+}
+
+func TestBoltStoreBackgroundTasks(t *testing.T) {
+	ctx := context.Background()
+	boltStore, err := NewStore(filepath.Join(t.TempDir(), "cache.db"), &Options{
+		SweepInterval:   10 * time.Millisecond,
+		CompactInterval: 30 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"short": "v"}, 20*time.Millisecond))
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"long": "v"}, time.Hour))
+
+	boltStore.mutex.RLock()
+	initial := boltStore.db
+	boltStore.mutex.RUnlock()
+
+	// 后台清理删除过期键的记录和过期索引
+	recordCount := func() (int, int) {
+		boltStore.mutex.RLock()
+		defer boltStore.mutex.RUnlock()
+		var records, indexes int
+		require.NoError(t, boltStore.db.View(func(tx *bolt.Tx) error {
+			records = tx.Bucket(dataBucket).Stats().KeyN
+			indexes = tx.Bucket(expiryBucket).Stats().KeyN
+			return nil
+		}))
+		return records, indexes
+	}
+	assert.Eventually(t, func() bool {
+		records, indexes := recordCount()
+		return records == 1 && indexes == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// 后台压缩替换数据文件，之后仍可正常读写
+	assert.Eventually(t, func() bool {
+		boltStore.mutex.RLock()
+		defer boltStore.mutex.RUnlock()
+		return boltStore.db != initial
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var result string
+	found, err := boltStore.Get(ctx, "long", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	require.NoError(t, boltStore.MSet(ctx, map[string]interface{}{"after": "v"}, 0))
+}
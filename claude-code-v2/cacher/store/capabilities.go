@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+)
+
+// 本文件定义Store的可选接口，调用方通过store.As或store.Capabilities判断Store是否支持
+
+// Closer 可选接口，Store持有连接、文件或后台任务时实现，用于释放资源
+type Closer interface {
+	// Close 释放Store持有的资源，关闭后不能再使用Store
+	Close() error
+}
+
+// Pinger 可选接口，Store依赖外部服务时实现，用于健康检查
+type Pinger interface {
+	// Ping 检查与后端的连接是否可用
+	Ping(ctx context.Context) error
+}
+
+// Flusher 可选接口，支持清空Store中的所有键
+type Flusher interface {
+	// Flush 删除Store中的所有键，共享后端(如同一个Redis数据库)中的其他数据也会被删除
+	Flush(ctx context.Context) error
+}
+
+// Scanner 可选接口，支持按模式遍历键
+type Scanner interface {
+	// Scan 遍历匹配pattern的键
+	// pattern: Redis风格的glob模式(支持* ? [...]和\转义)，空字符串匹配所有键
+	// cursor: 起始游标，0表示从头开始，其含义由具体实现决定
+	// count: 每次从后端取回的键数量提示，<=0时使用实现的默认值
+	// 返回: 键的迭代器，出错时以非nil错误作为最后一个元素
+	Scan(ctx context.Context, pattern string, cursor uint64, count int64) iter.Seq2[string, error]
+}
+
+// NoTTL Expirer.TTL中表示键存在但永不过期
+const NoTTL time.Duration = -1
+
+// Expirer 可选接口，支持读取和修改键的过期时间而不重写值
+type Expirer interface {
+	// TTL 批量读取键的剩余过期时间
+	// 返回: 键到剩余时间的映射，永不过期的键为NoTTL，不存在的键不包含在结果中
+	TTL(ctx context.Context, keys []string) (map[string]time.Duration, error)
+
+	// Expire 为已存在的键设置新的过期时间
+	// ttl: 新的过期时间，必须大于0
+	// 返回: 实际更新的键数量, 错误信息
+	Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error)
+
+	// Persist 移除键的过期时间，使其永不过期
+	// 返回: 原本有过期时间并被移除的键数量, 错误信息
+	Persist(ctx context.Context, keys []string) (int64, error)
+}
+
+// Counter 可选接口，支持原子计数器
+// 计数器以整数形式保存，可以用Get读取到整数类型的变量中
+type Counter interface {
+	// IncrBy 将键的值原子地增加delta(可以为负数)，键不存在时视为0
+	// ttl: 大于0时，仅在键没有过期时间(如新创建)时设置过期时间，已有的过期时间保持不变
+	// 返回: 增加后的值, 错误信息(如原值不是整数或溢出)
+	IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+
+	// MIncrBy 批量增加多个计数器，语义同IncrBy
+	// 返回: 键到增加后的值的映射, 错误信息
+	MIncrBy(ctx context.Context, deltas map[string]int64, ttl time.Duration) (map[string]int64, error)
+}
+
+// Version 值的版本，由Store生成，对调用方不透明，只能原样传回SetIfVersion
+type Version string
+
+// NoVersion 表示键不存在的版本，传给SetIfVersion时仅在键不存在时写入
+const NoVersion Version = ""
+
+// CAS 可选接口，支持基于版本的比较并交换，用于无锁的读-改-写
+type CAS interface {
+	// GetVersioned 获取单个值及其当前版本
+	// 返回: 值的版本(不存在时为NoVersion), 是否找到, 错误信息
+	GetVersioned(ctx context.Context, key string, dst interface{}) (Version, bool, error)
+
+	// SetIfVersion 仅当键的当前版本等于version时写入值
+	// version: GetVersioned返回的版本，NoVersion表示要求键不存在
+	// ttl: 过期时间，0表示永不过期
+	// 返回: 是否写入(版本不匹配时为false), 错误信息
+	SetIfVersion(ctx context.Context, key string, value interface{}, version Version, ttl time.Duration) (bool, error)
+}
+
+// ConditionalSetter 可选接口，支持按键是否存在有条件地批量写入
+// 每个键的检查和写入是原子的，但多个键之间不保证原子性
+type ConditionalSetter interface {
+	// MSetNX 仅写入不存在的键，已存在的键保持原值，适用于"先写入者胜出"的场景
+	// ttl: 过期时间，0表示永不过期
+	// 返回: 每个键是否被写入, 错误信息
+	MSetNX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error)
+
+	// MSetXX 仅写入已存在的键，不存在的键不会被创建
+	// ttl: 过期时间，0表示永不过期
+	// 返回: 每个键是否被写入, 错误信息
+	MSetXX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error)
+
+	// MSetEntriesNX 语义同MSetNX，每个键使用各自Entry的TTL
+	// 同一个键出现多次时以最后一个为准
+	// 返回: 每个键是否被写入, 错误信息
+	MSetEntriesNX(ctx context.Context, entries []Entry) (map[string]bool, error)
+}
+
+// Entry 带独立过期时间的键值对，用于EntrySetter.MSetEntries和ConditionalSetter.MSetEntriesNX
+type Entry struct {
+	Key   string
+	Value interface{}
+	// TTL 过期时间，0表示永不过期
+	TTL time.Duration
+}
+
+// EntrySetter 可选接口，支持在一次批量写入中为每个键指定不同的过期时间
+type EntrySetter interface {
+	// MSetEntries 批量设置键值对，每个键使用各自Entry的TTL
+	// 同一个键出现多次时以最后一个为准
+	// 返回: 错误信息
+	MSetEntries(ctx context.Context, entries []Entry) error
+}
+
+// PubSub 可选接口，支持跨进程广播消息，如通知其他进程使本地缓存失效
+// 消息不保证送达：订阅断开重连期间发布的消息会丢失
+type PubSub interface {
+	// Publish 向channel发布消息
+	Publish(ctx context.Context, channel string, message string) error
+
+	// Subscribe 订阅channel，收到的消息在后台goroutine中依次交给handler处理，直到ctx被取消
+	// 返回时订阅已经建立，之后发布的消息都会被收到
+	// 返回: 订阅建立失败时的错误信息
+	Subscribe(ctx context.Context, channel string, handler func(message string)) error
+}
+
+// EvictNotifier 可选接口，支持在键离开缓存时得到通知，如用于统计或触发刷新
+// 各Store能够报告的原因不同，见具体实现的说明；Flush不触发事件
+type EvictNotifier interface {
+	// OnEvict 注册回调，可以注册多个，按注册顺序调用
+	// 回调在产生事件的goroutine中同步执行，可能持有Store内部的锁，
+	// 应尽快返回，不能在回调中同步调用同一Store的方法
+	OnEvict(fn func(Event))
+}
+
+// Watcher 可选接口，支持订阅键的变化(写入、删除和过期)
+// 事件不保证送达：订阅者处理过慢时事件被丢弃(见WatchEvent.Missed)，连接断开期间的事件会丢失
+type Watcher interface {
+	// Watch 订阅匹配pattern的键的变化，ctx被取消时结束订阅并关闭channel
+	// pattern: Redis风格的glob模式，空字符串匹配所有键
+	// 返回: 事件channel, 订阅建立失败时的错误信息
+	Watch(ctx context.Context, pattern string) (<-chan WatchEvent, error)
+}
+
+// Wrapper 包装其他Store的Store实现该接口
+// 包装Store通常实现了全部可选接口并转发给被包装的Store，
+// As和Capabilities据此只报告被包装的Store同样支持的接口
+type Wrapper interface {
+	// Unwrap 返回被包装的Store
+	Unwrap() Store
+}
+
+// As 返回s实现的可选接口T
+// s实现Wrapper时，还要求被包装的Store(逐层)同样实现T
+func As[T any](s Store) (T, bool) {
+	var zero T
+	t, ok := s.(T)
+	if !ok {
+		return zero, false
+	}
+
+	for inner := s; ; {
+		wrapper, ok := inner.(Wrapper)
+		if !ok {
+			return t, true
+		}
+		inner = wrapper.Unwrap()
+		if _, ok := inner.(T); !ok {
+			return zero, false
+		}
+	}
+}
+
+// Capability Store支持的可选接口集合
+type Capability uint32
+
+const (
+	CapCloser Capability = 1 << iota
+	CapPinger
+	CapFlusher
+	CapScanner
+	CapExpirer
+	CapCounter
+	CapCAS
+	CapConditionalSetter
+	CapPubSub
+	CapEntrySetter
+	CapEvictNotifier
+	CapWatcher
+)
+
+// capabilityNames 与Capability各位对应的接口名
+var capabilityNames = []string{
+	"Closer",
+	"Pinger",
+	"Flusher",
+	"Scanner",
+	"Expirer",
+	"Counter",
+	"CAS",
+	"ConditionalSetter",
+	"PubSub",
+	"EntrySetter",
+	"EvictNotifier",
+	"Watcher",
+}
+
+// Has 判断是否包含other中的所有接口
+func (c Capability) Has(other Capability) bool {
+	return c&other == other
+}
+
+// String 返回以|分隔的接口名，如"Closer|Scanner"
+func (c Capability) String() string {
+	names := make([]string, 0, len(capabilityNames))
+	for i, name := range capabilityNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// Capabilities 返回s支持的可选接口，包装Store按As的规则判断
+func Capabilities(s Store) Capability {
+	var c Capability
+	add := func(capability Capability, ok bool) {
+		if ok {
+			c |= capability
+		}
+	}
+
+	_, ok := As[Closer](s)
+	add(CapCloser, ok)
+	_, ok = As[Pinger](s)
+	add(CapPinger, ok)
+	_, ok = As[Flusher](s)
+	add(CapFlusher, ok)
+	_, ok = As[Scanner](s)
+	add(CapScanner, ok)
+	_, ok = As[Expirer](s)
+	add(CapExpirer, ok)
+	_, ok = As[Counter](s)
+	add(CapCounter, ok)
+	_, ok = As[CAS](s)
+	add(CapCAS, ok)
+	_, ok = As[ConditionalSetter](s)
+	add(CapConditionalSetter, ok)
+	_, ok = As[PubSub](s)
+	add(CapPubSub, ok)
+	_, ok = As[EntrySetter](s)
+	add(CapEntrySetter, ok)
+	_, ok = As[EvictNotifier](s)
+	add(CapEvictNotifier, ok)
+	_, ok = As[Watcher](s)
+	add(CapWatcher, ok)
+
+	return c
+}
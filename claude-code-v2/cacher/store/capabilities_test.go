@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// baseStore 只实现Store接口
+type baseStore struct{}
+
+func (baseStore) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	return false, nil
+}
+
+func (baseStore) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	return nil
+}
+
+func (baseStore) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+func (baseStore) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	return nil
+}
+
+func (baseStore) Del(ctx context.Context, keys ...string) (int64, error) {
+	return 0, nil
+}
+
+// closingStore 额外实现Closer和Pinger
+type closingStore struct {
+	baseStore
+}
+
+func (closingStore) Close() error {
+	return nil
+}
+
+func (closingStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// wrappingStore 实现Closer、Pinger和Flusher并包装其他Store
+type wrappingStore struct {
+	closingStore
+	inner Store
+}
+
+func (w wrappingStore) Unwrap() Store {
+	return w.inner
+}
+
+func (wrappingStore) Flush(ctx context.Context) error {
+	return nil
+}
+
+func TestCapabilities(t *testing.T) {
+	assert.Equal(t, Capability(0), Capabilities(baseStore{}))
+	assert.Equal(t, CapCloser|CapPinger, Capabilities(closingStore{}))
+
+	// 包装Store只报告被包装的Store同样支持的接口
+	assert.Equal(t, CapCloser|CapPinger, Capabilities(wrappingStore{inner: closingStore{}}))
+	assert.Equal(t, Capability(0), Capabilities(wrappingStore{inner: baseStore{}}))
+
+	// 多层包装逐层检查
+	nested := wrappingStore{inner: wrappingStore{inner: closingStore{}}}
+	assert.Equal(t, CapCloser|CapPinger, Capabilities(nested))
+
+	_, ok := As[Pinger](wrappingStore{inner: closingStore{}})
+	assert.True(t, ok)
+	_, ok = As[Flusher](wrappingStore{inner: closingStore{}})
+	assert.False(t, ok)
+}
+
+func TestCapabilityString(t *testing.T) {
+	assert.Equal(t, "none", Capability(0).String())
+	assert.Equal(t, "Closer|Scanner|PubSub", (CapCloser | CapScanner | CapPubSub).String())
+
+	c := CapCloser | CapExpirer
+	assert.True(t, c.Has(CapExpirer))
+	assert.True(t, c.Has(CapCloser|CapExpirer))
+	assert.False(t, c.Has(CapExpirer|CapCounter))
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"sync"
+)
+
+// ForChunks 将[0, n)按size拆分为多个区间，以最多parallelism个并发执行fn
+// size<=0或只有一个批次时直接在当前goroutine执行；任一批次出错时取消其余批次并返回第一个错误
+func ForChunks(ctx context.Context, n, size, parallelism int, fn func(ctx context.Context, start, end int) error) error {
+	if size <= 0 || n <= size {
+		return fn(ctx, 0, n)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, max(parallelism, 1))
+	for start := 0; start < n; start += size {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, start, end); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(start, min(start+size, n))
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForChunks(t *testing.T) {
+	ctx := context.Background()
+
+	// 每个区间恰好执行一次
+	var mutex sync.Mutex
+	var ranges [][2]int
+	err := ForChunks(ctx, 10, 3, 2, func(ctx context.Context, start, end int) error {
+		mutex.Lock()
+		ranges = append(ranges, [2]int{start, end})
+		mutex.Unlock()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, [][2]int{{0, 3}, {3, 6}, {6, 9}, {9, 10}}, ranges)
+
+	// size<=0时不拆分
+	calls := 0
+	assert.NoError(t, ForChunks(ctx, 10, 0, 2, func(ctx context.Context, start, end int) error {
+		calls++
+		assert.Equal(t, [2]int{0, 10}, [2]int{start, end})
+		return nil
+	}))
+	assert.Equal(t, 1, calls)
+
+	// 返回第一个错误并取消其余批次
+	failure := errors.New("failed")
+	var executed atomic.Int64
+	err = ForChunks(ctx, 100, 1, 1, func(ctx context.Context, start, end int) error {
+		executed.Add(1)
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Less(t, executed.Load(), int64(100))
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Codec 值编解码器，供需要把值序列化为字节的存储后端使用
+type Codec interface {
+	// Marshal 将值编码为字节
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal 将字节解码到目标变量
+	// v: 目标变量的指针
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec 基于encoding/json的编解码器
+type JSONCodec struct{}
+
+// Marshal 将值编码为JSON
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 将JSON解码到目标变量
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// DefaultCodec 未指定编解码器时使用的默认编解码器
+var DefaultCodec Codec = JSONCodec{}
+
+// RawCodec 透传编解码器，值必须是[]byte或string，按原始字节存取
+// 适用于值已在上游完成序列化的场景，如缓存服务器代理客户端的数据
+type RawCodec struct{}
+
+// Marshal 返回值的原始字节
+func (RawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case []byte:
+		return val, nil
+	case string:
+		return []byte(val), nil
+	default:
+		return nil, fmt.Errorf("raw codec: unsupported value type %T", v)
+	}
+}
+
+// Unmarshal 将原始字节复制到目标变量
+func (RawCodec) Unmarshal(data []byte, v interface{}) error {
+	switch dst := v.(type) {
+	case *[]byte:
+		*dst = append([]byte{}, data...)
+	case *string:
+		*dst = string(data)
+	case *interface{}:
+		*dst = append([]byte{}, data...)
+	default:
+		return fmt.Errorf("raw codec: unsupported destination type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+)
+
+// BatchError 批量操作中部分键失败时返回的错误
+// 失败的键被跳过，其余键的结果仍然有效，调用方可以用errors.As检查失败的键
+type BatchError struct {
+	// Errors 失败的键到对应错误的映射
+	Errors map[string]error
+}
+
+// Add 记录键的错误
+func (e *BatchError) Add(key string, err error) {
+	if e.Errors == nil {
+		e.Errors = make(map[string]error)
+	}
+	e.Errors[key] = err
+}
+
+// Keys 返回排序后的失败键
+func (e *BatchError) Keys() []string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Err 没有失败的键时返回nil，否则返回e本身
+func (e *BatchError) Err() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error 返回失败键的数量和第一个失败键的错误
+func (e *BatchError) Error() string {
+	keys := e.Keys()
+	switch len(keys) {
+	case 0:
+		return "batch error: no failed keys"
+	case 1:
+		return fmt.Sprintf("key %s: %v", keys[0], e.Errors[keys[0]])
+	default:
+		return fmt.Sprintf("%d keys failed, first key %s: %v", len(keys), keys[0], e.Errors[keys[0]])
+	}
+}
+
+// Unwrap 返回所有键的错误，使errors.Is和errors.As可以匹配其中任意一个
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, key := range e.Keys() {
+		errs = append(errs, e.Errors[key])
+	}
+	return errs
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchError(t *testing.T) {
+	var empty BatchError
+	assert.NoError(t, empty.Err())
+
+	errBad := errors.New("bad value")
+	e := &BatchError{}
+	e.Add("b", errBad)
+	e.Add("a", fmt.Errorf("decode: %w", errBad))
+
+	assert.Equal(t, []string{"a", "b"}, e.Keys())
+	assert.Equal(t, "2 keys failed, first key a: decode: bad value", e.Error())
+
+	// 包装后仍可以用errors.As取出，并匹配任意一个键的错误
+	err := fmt.Errorf("mget: %w", e.Err())
+	var batchErr *BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Same(t, e, batchErr)
+	assert.ErrorIs(t, err, errBad)
+
+	single := &BatchError{}
+	single.Add("k", errBad)
+	assert.Equal(t, "key k: bad value", single.Error())
+}
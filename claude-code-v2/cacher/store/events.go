@@ -0,0 +1,72 @@
+package store
+
+import "sync"
+
+// EvictReason 键离开缓存的原因
+type EvictReason int
+
+const (
+	// EvictCapacity 容量不足被淘汰，或写入时被准入策略拒绝
+	EvictCapacity EvictReason = iota + 1
+	// EvictExpired 过期
+	EvictExpired
+	// EvictDeleted 被删除
+	EvictDeleted
+	// EvictReplaced 被新值覆盖，只修改过期时间不算覆盖
+	EvictReplaced
+)
+
+// String 返回原因的名称
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	case EvictReplaced:
+		return "replaced"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 键离开缓存的事件
+type Event struct {
+	Key    string
+	Reason EvictReason
+	// Value 离开缓存的值，只有内存Store(如Ristretto)能够提供，其他Store为nil
+	Value interface{}
+}
+
+// EvictHandlers 并发安全的OnEvict回调列表，供Store实现EvictNotifier
+type EvictHandlers struct {
+	mutex    sync.RWMutex
+	handlers []func(Event)
+}
+
+// Add 注册回调
+func (h *EvictHandlers) Add(fn func(Event)) {
+	h.mutex.Lock()
+	h.handlers = append(h.handlers, fn)
+	h.mutex.Unlock()
+}
+
+// Active 判断是否注册了回调，没有回调时Store可以跳过事件相关的额外开销
+func (h *EvictHandlers) Active() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.handlers) > 0
+}
+
+// Emit 按注册顺序依次调用回调
+func (h *EvictHandlers) Emit(event Event) {
+	h.mutex.RLock()
+	handlers := h.handlers
+	h.mutex.RUnlock()
+
+	for _, fn := range handlers {
+		fn(event)
+	}
+}
@@ -0,0 +1,31 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictHandlers(t *testing.T) {
+	var handlers EvictHandlers
+	assert.False(t, handlers.Active())
+
+	// 没有回调时Emit不做任何事
+	handlers.Emit(Event{Key: "a", Reason: EvictDeleted})
+
+	var calls []string
+	handlers.Add(func(event Event) { calls = append(calls, "first:"+event.Key+":"+event.Reason.String()) })
+	handlers.Add(func(event Event) { calls = append(calls, "second:"+event.Key+":"+event.Reason.String()) })
+	assert.True(t, handlers.Active())
+
+	handlers.Emit(Event{Key: "a", Reason: EvictExpired})
+	assert.Equal(t, []string{"first:a:expired", "second:a:expired"}, calls)
+}
+
+func TestEvictReasonString(t *testing.T) {
+	assert.Equal(t, "capacity", EvictCapacity.String())
+	assert.Equal(t, "expired", EvictExpired.String())
+	assert.Equal(t, "deleted", EvictDeleted.String())
+	assert.Equal(t, "replaced", EvictReplaced.String())
+	assert.Equal(t, "unknown", EvictReason(0).String())
+}
@@ -0,0 +1,609 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+const (
+	// magic 缓存文件魔数
+	magic = "GCFS"
+	// formatVersion 缓存文件格式版本
+	formatVersion = 1
+	// fixedHeaderSize 固定头长度：魔数 + 版本 + 过期时间(UnixNano) + 键长度
+	fixedHeaderSize = len(magic) + 1 + 8 + 2
+	// tmpPrefix 写入中的临时文件前缀，遍历时会被跳过
+	tmpPrefix = ".tmp-"
+	// lockFileName 多进程淘汰时使用的锁文件
+	lockFileName = ".lock"
+	// staleTmpAge 超过该时长的临时文件视为崩溃残留，淘汰时一并清理
+	staleTmpAge = time.Hour
+)
+
+// errCorrupt 缓存文件头不合法
+var errCorrupt = errors.New("corrupt cache file")
+
+// Options FS Store配置
+type Options struct {
+	// Codec 值编解码器，为nil时使用store.DefaultCodec
+	Codec store.Codec
+
+	// MaxSize 所有缓存文件的最大总字节数，0表示不限制
+	// 超出后按访问时间淘汰最久未访问的文件，直到总大小降到MaxSize的90%
+	// 是否超出由每个进程各自估算：估算值为上次淘汰时扫描到的总大小加上本进程之后的写入，
+	// 不包含其他进程的写入，因此多个进程共享目录时实际总大小可能超过MaxSize，
+	// 直到某个进程的估算值超出并扫描整个目录按实际大小淘汰
+	MaxSize int64
+
+	// FileMode 缓存文件权限，0使用默认值0644
+	FileMode os.FileMode
+
+	// DirMode 分片目录权限，0使用默认值0755
+	DirMode os.FileMode
+
+	// DeleteCorrupt 启用后MGet中无法读取或解码的键在返回*store.BatchError的同时被删除
+	DeleteCorrupt bool
+}
+
+// Store 基于文件系统的Store实现
+// 每个键保存为一个文件，路径由键的SHA-256哈希分两级分片得到。
+// 写入使用临时文件+rename保证原子性，文件的修改时间作为访问时间用于LRU淘汰，
+// 删除前确认路径上仍是读取过的文件(os.SameFile)，不会删除其他进程刚替换的新文件，
+// 多个进程可以安全地共享同一个目录。
+type Store struct {
+	dir   string
+	opts  Options
+	codec store.Codec
+
+	// size 本进程估算的缓存总大小，不包含其他进程的写入，淘汰时在锁内根据实际扫描结果校准
+	size       atomic.Int64
+	evictMutex sync.Mutex
+}
+
+// NewStore 以dir为根目录创建新的FS Store实例
+// opts: 配置项，可以为nil使用默认配置
+func NewStore(dir string, opts *Options) (*Store, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+	if o.FileMode == 0 {
+		o.FileMode = 0644
+	}
+	if o.DirMode == 0 {
+		o.DirMode = 0755
+	}
+
+	if err := os.MkdirAll(dir, o.DirMode); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	s := &Store{
+		dir:   dir,
+		opts:  o,
+		codec: o.Codec,
+	}
+
+	if o.MaxSize > 0 {
+		if err := s.evict(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Get 读取键对应的文件并解码到dst
+func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	found, _, err := s.get(key, dst)
+	return found, err
+}
+
+// get 读取键对应的文件并解码到dst
+// 返回: 是否找到, 读取到的文件信息(文件不存在时为nil), 错误信息
+func (s *Store) get(key string, dst interface{}) (bool, os.FileInfo, error) {
+	path := s.path(key)
+	data, info, err := readFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, info, fmt.Errorf("fs get error: %w", err)
+	}
+
+	storedKey, expiresAt, payload, err := decodeFile(data)
+	if err != nil {
+		return false, info, fmt.Errorf("fs get error for key %s: %w", key, err)
+	}
+	// 哈希冲突时视为未命中
+	if storedKey != key {
+		return false, info, nil
+	}
+	if isExpired(expiresAt, time.Now().UnixNano()) {
+		s.remove(path, info)
+		return false, info, nil
+	}
+
+	if err := s.codec.Unmarshal(payload, dst); err != nil {
+		return false, info, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	s.touch(path)
+	return true, info, nil
+}
+
+// MGet 批量获取值到map中
+func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 验证dstMap是map指针
+	dstMapValue := reflect.ValueOf(dstMap)
+	if dstMapValue.Kind() != reflect.Ptr || dstMapValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("dstMap must be a pointer to map")
+	}
+
+	mapValue := dstMapValue.Elem()
+	mapType := mapValue.Type()
+	valueType := mapType.Elem()
+
+	// 如果map为nil，初始化它
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapType))
+	}
+
+	// 无法读取或解码的键跳过并记录，不影响其他键
+	batchErr := &store.BatchError{}
+	corrupt := make(map[string]os.FileInfo)
+	for _, key := range keys {
+		// 创建值类型的新实例
+		valuePtr := reflect.New(valueType)
+
+		found, info, err := s.get(key, valuePtr.Interface())
+		if err != nil {
+			batchErr.Add(key, err)
+			if info != nil {
+				corrupt[key] = info
+			}
+			continue
+		}
+		if !found {
+			continue
+		}
+
+		mapValue.SetMapIndex(reflect.ValueOf(key), valuePtr.Elem())
+	}
+
+	if s.opts.DeleteCorrupt {
+		// 文件头无法解析时Del无法确认文件属于该键，因此直接删除文件
+		// 删除失败不影响返回结果，下次读取时会再次尝试
+		for key, info := range corrupt {
+			s.remove(s.path(key), info)
+		}
+	}
+	return batchErr.Err()
+}
+
+// Exists 批量检查键存在性，只读取文件头
+func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	now := time.Now().UnixNano()
+	for _, key := range keys {
+		storedKey, expiresAt, _, err := readHeader(s.path(key))
+		// 文件头损坏时视为不存在
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errCorrupt) {
+			result[key] = false
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fs exists error for key %s: %w", key, err)
+		}
+		result[key] = storedKey == key && !isExpired(expiresAt, now)
+	}
+
+	return result, nil
+}
+
+// MSet 批量写入键值对，每个文件通过临时文件+rename原子替换
+func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	for key, value := range items {
+		payload, err := s.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value for key %s: %w", key, err)
+		}
+
+		data, err := encodeFile(key, expiresAt, payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode file for key %s: %w", key, err)
+		}
+
+		if err := s.writeFile(s.path(key), data); err != nil {
+			return fmt.Errorf("fs set error for key %s: %w", key, err)
+		}
+	}
+
+	if s.opts.MaxSize > 0 && s.size.Load() > s.opts.MaxSize {
+		if err := s.evict(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Del 删除指定键，已过期的文件同样会被删除但不计入删除数量
+func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var deletedCount int64
+	now := time.Now().UnixNano()
+	for _, key := range keys {
+		path := s.path(key)
+		storedKey, expiresAt, info, err := readHeader(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		// 文件头损坏时无法确认文件属于该键，直接删除但不计入删除数量
+		if errors.Is(err, errCorrupt) {
+			s.remove(path, info)
+			continue
+		}
+		if err != nil {
+			return deletedCount, fmt.Errorf("fs del error for key %s: %w", key, err)
+		}
+		if storedKey != key {
+			continue
+		}
+
+		if s.remove(path, info) && !isExpired(expiresAt, now) {
+			deletedCount++
+		}
+	}
+
+	return deletedCount, nil
+}
+
+// path 返回键对应的文件路径：<dir>/<hash[0:2]>/<hash[2:4]>/<hash>
+func (s *Store) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, name[0:2], name[2:4], name)
+}
+
+// writeFile 通过同目录下的临时文件+rename原子写入
+func (s *Store) writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, s.opts.DirMode); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Chmod(s.opts.FileMode); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	// rename之前落盘，避免崩溃后路径指向内容不完整的文件
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	var oldSize int64
+	if info, err := os.Stat(path); err == nil {
+		oldSize = info.Size()
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	s.size.Add(int64(len(data)) - oldSize)
+	return nil
+}
+
+// remove 删除读取过的文件并更新估算大小，返回是否由本次调用删除
+func (s *Store) remove(path string, info os.FileInfo) bool {
+	if !removeSame(path, info) {
+		return false
+	}
+	s.size.Add(-info.Size())
+	return true
+}
+
+// removeSame 只在路径上仍是info对应的文件时删除，返回是否删除
+// 其他进程可能在读取之后通过rename替换了文件，此时路径指向新文件，不能删除
+func removeSame(path string, info os.FileInfo) bool {
+	current, err := os.Lstat(path)
+	if err != nil || !os.SameFile(info, current) {
+		return false
+	}
+	return os.Remove(path) == nil
+}
+
+// touch 更新文件的修改时间作为访问时间
+// 很多文件系统以noatime/relatime挂载，atime不可靠，因此使用mtime记录访问时间
+func (s *Store) touch(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
+
+// fileEntry 淘汰扫描时收集的文件信息
+type fileEntry struct {
+	path string
+	info os.FileInfo
+}
+
+// evict 扫描整个目录，删除过期文件，并按访问时间淘汰直到总大小低于阈值
+// 通过锁文件保证同一时间只有一个进程在执行淘汰
+func (s *Store) evict() error {
+	s.evictMutex.Lock()
+	defer s.evictMutex.Unlock()
+
+	lock, err := os.OpenFile(filepath.Join(s.dir, lockFileName), os.O_CREATE|os.O_RDWR, s.opts.FileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer lock.Close()
+
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock cache dir: %w", err)
+	}
+	defer unlockFile(lock)
+
+	now := time.Now()
+	var entries []fileEntry
+	var total int64
+
+	err = filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 其他进程可能在遍历期间删除了文件或目录
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || d.Name() == lockFileName {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		if strings.HasPrefix(d.Name(), tmpPrefix) {
+			if now.Sub(info.ModTime()) > staleTmpAge {
+				os.Remove(path)
+			}
+			return nil
+		}
+
+		_, expiresAt, headerInfo, err := readHeader(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || isExpired(expiresAt, now.UnixNano()) {
+			if headerInfo != nil {
+				info = headerInfo
+			}
+			removeSame(path, info)
+			return nil
+		}
+
+		entries = append(entries, fileEntry{path: path, info: headerInfo})
+		total += headerInfo.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan cache dir: %w", err)
+	}
+
+	if s.opts.MaxSize > 0 && total > s.opts.MaxSize {
+		target := s.opts.MaxSize / 10 * 9
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].info.ModTime().Before(entries[j].info.ModTime())
+		})
+		for _, entry := range entries {
+			if total <= target {
+				break
+			}
+			// 扫描后被其他进程替换的文件是刚写入的，不淘汰
+			if removeSame(entry.path, entry.info) {
+				total -= entry.info.Size()
+			} else if _, err := os.Lstat(entry.path); errors.Is(err, fs.ErrNotExist) {
+				total -= entry.info.Size()
+			}
+		}
+	}
+
+	s.size.Store(total)
+	return nil
+}
+
+// Flush 删除所有分片目录，与淘汰使用同一把锁
+func (s *Store) Flush(ctx context.Context) error {
+	s.evictMutex.Lock()
+	defer s.evictMutex.Unlock()
+
+	lock, err := os.OpenFile(filepath.Join(s.dir, lockFileName), os.O_CREATE|os.O_RDWR, s.opts.FileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer lock.Close()
+
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock cache dir: %w", err)
+	}
+	defer unlockFile(lock)
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache dir: %w", err)
+	}
+
+	// 只删除两位十六进制命名的分片目录，不影响目录中的其他文件
+	for _, entry := range entries {
+		if !entry.IsDir() || len(entry.Name()) != 2 {
+			continue
+		}
+		if _, err := hex.DecodeString(entry.Name()); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(s.dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove cache shard: %w", err)
+		}
+	}
+
+	s.size.Store(0)
+	return nil
+}
+
+// encodeFile 生成缓存文件内容：文件头 + 键 + 编码后的值
+func encodeFile(key string, expiresAt int64, payload []byte) ([]byte, error) {
+	if len(key) > math.MaxUint16 {
+		return nil, fmt.Errorf("key too long: %d bytes", len(key))
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, fixedHeaderSize+len(key)+len(payload)))
+	buf.WriteString(magic)
+	buf.WriteByte(formatVersion)
+	binary.Write(buf, binary.BigEndian, expiresAt)
+	binary.Write(buf, binary.BigEndian, uint16(len(key)))
+	buf.WriteString(key)
+	buf.Write(payload)
+	return buf.Bytes(), nil
+}
+
+// decodeFile 解析缓存文件内容
+func decodeFile(data []byte) (string, int64, []byte, error) {
+	if len(data) < fixedHeaderSize || string(data[:len(magic)]) != magic || data[len(magic)] != formatVersion {
+		return "", 0, nil, errCorrupt
+	}
+
+	offset := len(magic) + 1
+	expiresAt := int64(binary.BigEndian.Uint64(data[offset:]))
+	keyLen := int(binary.BigEndian.Uint16(data[offset+8:]))
+	if len(data) < fixedHeaderSize+keyLen {
+		return "", 0, nil, errCorrupt
+	}
+
+	key := string(data[fixedHeaderSize : fixedHeaderSize+keyLen])
+	return key, expiresAt, data[fixedHeaderSize+keyLen:], nil
+}
+
+// readFile 读取整个文件，同时返回所读文件的信息，用于删除前确认文件未被替换
+func readFile(path string) ([]byte, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, info, err
+	}
+	return data, info, nil
+}
+
+// readHeader 只读取文件头中的键和过期时间，同时返回所读文件的信息
+// 文件头不合法时返回errCorrupt和文件信息
+func readHeader(path string) (string, int64, os.FileInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	header := make([]byte, fixedHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return "", 0, info, errCorrupt
+	}
+	if string(header[:len(magic)]) != magic || header[len(magic)] != formatVersion {
+		return "", 0, info, errCorrupt
+	}
+
+	offset := len(magic) + 1
+	expiresAt := int64(binary.BigEndian.Uint64(header[offset:]))
+	key := make([]byte, binary.BigEndian.Uint16(header[offset+8:]))
+	if _, err := io.ReadFull(f, key); err != nil {
+		return "", 0, info, errCorrupt
+	}
+
+	return string(key), expiresAt, info, nil
+}
+
+// isExpired 检查过期时间是否已到
+func isExpired(expiresAt, now int64) bool {
+	return expiresAt != 0 && now >= expiresAt
+}
+
+// 确保Store实现了store.Store和store.Flusher接口
+var (
+	_ store.Store   = (*Store)(nil)
+	_ store.Flusher = (*Store)(nil)
+)
@@ -0,0 +1,208 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+)
+
+func TestFSStore(t *testing.T) {
+	// 创建FS Store
+	fsStore, err := NewStore(t.TempDir(), nil)
+	require.NoError(t, err)
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, fsStore)
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+}
+
+func TestFSStoreEvictsLeastRecentlyAccessed(t *testing.T) {
+	ctx := context.Background()
+	value := strings.Repeat("x", 100)
+
+	fsStore, err := NewStore(t.TempDir(), &Options{MaxSize: 1000})
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("lru_key_%d", i)
+		require.NoError(t, fsStore.MSet(ctx, map[string]interface{}{key: value}, 0))
+
+		// 人为拉开访问时间，避免文件系统时间精度影响排序
+		past := time.Now().Add(time.Duration(i-10) * time.Minute)
+		require.NoError(t, os.Chtimes(fsStore.path(key), past, past))
+	}
+
+	// 访问最早写入的键，使其变为最近访问
+	var result string
+	found, err := fsStore.Get(ctx, "lru_key_0", &result)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	// 继续写入触发淘汰
+	for i := 5; i < 10; i++ {
+		key := fmt.Sprintf("lru_key_%d", i)
+		require.NoError(t, fsStore.MSet(ctx, map[string]interface{}{key: value}, 0))
+	}
+
+	assert.LessOrEqual(t, fsStore.size.Load(), int64(1000))
+
+	exists, err := fsStore.Exists(ctx, []string{"lru_key_0", "lru_key_1", "lru_key_9"})
+	require.NoError(t, err)
+	assert.True(t, exists["lru_key_0"])
+	assert.False(t, exists["lru_key_1"])
+	assert.True(t, exists["lru_key_9"])
+}
+
+func TestFSStoreSharedDirectory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// 两个实例模拟共享同一目录的两个进程
+	writer, err := NewStore(dir, nil)
+	require.NoError(t, err)
+	reader, err := NewStore(dir, nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			value := strings.Repeat(fmt.Sprint(i%10), 1000)
+			assert.NoError(t, writer.MSet(ctx, map[string]interface{}{"shared": value}, 0))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			// 原子替换保证读取到的始终是完整的值
+			var result string
+			found, err := reader.Get(ctx, "shared", &result)
+			assert.NoError(t, err)
+			if found {
+				assert.Len(t, result, 1000)
+				assert.Equal(t, strings.Repeat(result[:1], 1000), result)
+			}
+		}
+	}()
+	wg.Wait()
+
+	// 淘汰扫描不应删除有效文件
+	require.NoError(t, writer.evict())
+	var result string
+	found, err := reader.Get(ctx, "shared", &result)
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestFSStoreDeleteCorrupt(t *testing.T) {
+	ctx := context.Background()
+
+	fsStore, err := NewStore(t.TempDir(), &Options{DeleteCorrupt: true})
+	require.NoError(t, err)
+	require.NoError(t, fsStore.MSet(ctx, map[string]interface{}{"good": "value", "truncated": "value", "payload": "value"}, 0))
+
+	// 文件头被截断、内容完全无法识别以及值无法解码的文件
+	data, err := os.ReadFile(fsStore.path("truncated"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(fsStore.path("truncated"), data[:fixedHeaderSize-2], 0644))
+	require.NoError(t, os.MkdirAll(filepath.Dir(fsStore.path("garbage")), 0755))
+	require.NoError(t, os.WriteFile(fsStore.path("garbage"), []byte("not a cache file"), 0644))
+	data, err = encodeFile("payload", 0, []byte("{not json"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(fsStore.path("payload"), data, 0644))
+
+	values := make(map[string]string)
+	err = fsStore.MGet(ctx, []string{"good", "truncated", "garbage", "payload"}, &values)
+	var batchErr *store.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.ElementsMatch(t, []string{"truncated", "garbage", "payload"}, batchErr.Keys())
+	assert.Equal(t, map[string]string{"good": "value"}, values)
+
+	// 无法读取的文件已被删除
+	for _, key := range []string{"truncated", "garbage", "payload"} {
+		_, err := os.Stat(fsStore.path(key))
+		assert.ErrorIs(t, err, os.ErrNotExist, key)
+	}
+	values = make(map[string]string)
+	require.NoError(t, fsStore.MGet(ctx, []string{"good", "truncated", "garbage", "payload"}, &values))
+	assert.Equal(t, map[string]string{"good": "value"}, values)
+}
+
+func TestFSStoreCorruptHeader(t *testing.T) {
+	ctx := context.Background()
+
+	fsStore, err := NewStore(t.TempDir(), nil)
+	require.NoError(t, err)
+	require.NoError(t, fsStore.MSet(ctx, map[string]interface{}{"good": "value", "bad": "value"}, 0))
+	require.NoError(t, os.WriteFile(fsStore.path("bad"), []byte("not a cache file"), 0644))
+
+	// 文件头损坏的键视为不存在，不影响同一批次的其他键
+	exists, err := fsStore.Exists(ctx, []string{"good", "bad"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"good": true, "bad": false}, exists)
+
+	// Del删除损坏的文件但不计入删除数量
+	deleted, err := fsStore.Del(ctx, "bad", "good")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	for _, key := range []string{"good", "bad"} {
+		_, err := os.Stat(fsStore.path(key))
+		assert.ErrorIs(t, err, os.ErrNotExist, key)
+	}
+}
+
+func TestFSStoreRemoveReplaced(t *testing.T) {
+	ctx := context.Background()
+
+	fsStore, err := NewStore(t.TempDir(), nil)
+	require.NoError(t, err)
+	require.NoError(t, fsStore.MSet(ctx, map[string]interface{}{"key": "old"}, 0))
+
+	path := fsStore.path("key")
+	_, _, info, err := readHeader(path)
+	require.NoError(t, err)
+
+	// 读取之后其他进程以rename替换了文件，按旧文件信息删除时不能删除新文件
+	other, err := NewStore(fsStore.dir, nil)
+	require.NoError(t, err)
+	require.NoError(t, other.MSet(ctx, map[string]interface{}{"key": "new"}, 0))
+	assert.False(t, fsStore.remove(path, info))
+
+	var result string
+	found, err := fsStore.Get(ctx, "key", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "new", result)
+
+	// 文件未被替换时正常删除
+	_, _, info, err = readHeader(path)
+	require.NoError(t, err)
+	assert.True(t, fsStore.remove(path, info))
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestFSStoreFileFormat(t *testing.T) {
+	data, err := encodeFile("key", 42, []byte(`"value"`))
+	require.NoError(t, err)
+
+	key, expiresAt, payload, err := decodeFile(data)
+	require.NoError(t, err)
+	assert.Equal(t, "key", key)
+	assert.Equal(t, int64(42), expiresAt)
+	assert.Equal(t, `"value"`, string(payload))
+
+	_, _, _, err = decodeFile(data[:5])
+	assert.ErrorIs(t, err, errCorrupt)
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package fs
+
+import "os"
+
+// lockFile 非unix平台不支持flock，仅依赖进程内的互斥锁
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile 非unix平台不支持flock
+func unlockFile(f *os.File) error {
+	return nil
+}
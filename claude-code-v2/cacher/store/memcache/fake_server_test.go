@@ -0,0 +1,233 @@
+package memcache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeItem 模拟服务器中保存的缓存项
+type fakeItem struct {
+	value     []byte
+	flags     uint32
+	expiresAt time.Time
+	cas       uint64
+}
+
+// fakeServer 进程内的memcached文本协议模拟服务器，仅实现测试所需的命令
+type fakeServer struct {
+	listener net.Listener
+
+	mutex   sync.Mutex
+	items   map[string]*fakeItem
+	nextCAS uint64
+}
+
+// newFakeServer 启动模拟服务器，测试结束时自动关闭
+func newFakeServer(t *testing.T) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	fs := &fakeServer{
+		listener: listener,
+		items:    make(map[string]*fakeItem),
+	}
+	go fs.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return fs
+}
+
+// Addr 返回服务器监听地址
+func (fs *fakeServer) Addr() string {
+	return fs.listener.Addr().String()
+}
+
+func (fs *fakeServer) serve() {
+	for {
+		conn, err := fs.listener.Accept()
+		if err != nil {
+			return
+		}
+		go fs.handle(conn)
+	}
+}
+
+func (fs *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			fmt.Fprint(rw, "ERROR\r\n")
+			rw.Flush()
+			continue
+		}
+
+		switch fields[0] {
+		case "get", "gets":
+			fs.handleGet(rw, fields[1:])
+		case "set", "add", "replace", "cas":
+			if err := fs.handleStore(rw, fields); err != nil {
+				return
+			}
+		case "delete":
+			fs.handleDelete(rw, fields[1:])
+		case "touch":
+			fs.handleTouch(rw, fields[1:])
+		case "version":
+			fmt.Fprint(rw, "VERSION fake\r\n")
+		case "flush_all":
+			fs.mutex.Lock()
+			fs.items = make(map[string]*fakeItem)
+			fs.mutex.Unlock()
+			fmt.Fprint(rw, "OK\r\n")
+		default:
+			fmt.Fprint(rw, "ERROR\r\n")
+		}
+		rw.Flush()
+	}
+}
+
+// lookup 获取未过期的缓存项，调用方需持有锁
+func (fs *fakeServer) lookup(key string) *fakeItem {
+	item, ok := fs.items[key]
+	if !ok {
+		return nil
+	}
+	if !item.expiresAt.IsZero() && !time.Now().Before(item.expiresAt) {
+		delete(fs.items, key)
+		return nil
+	}
+	return item
+}
+
+func (fs *fakeServer) handleGet(rw *bufio.ReadWriter, keys []string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	for _, key := range keys {
+		if item := fs.lookup(key); item != nil {
+			fmt.Fprintf(rw, "VALUE %s %d %d %d\r\n", key, item.flags, len(item.value), item.cas)
+			rw.Write(item.value)
+			rw.WriteString("\r\n")
+		}
+	}
+	rw.WriteString("END\r\n")
+}
+
+func (fs *fakeServer) handleStore(rw *bufio.ReadWriter, fields []string) error {
+	if len(fields) < 5 {
+		fmt.Fprint(rw, "CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+
+	verb, key := fields[0], fields[1]
+	flags, _ := strconv.ParseUint(fields[2], 10, 32)
+	exptime, _ := strconv.ParseInt(fields[3], 10, 64)
+	size, err := strconv.Atoi(fields[4])
+	if err != nil {
+		fmt.Fprint(rw, "CLIENT_ERROR bad data chunk\r\n")
+		return nil
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(rw, data); err != nil {
+		return err
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	existing := fs.lookup(key)
+	switch verb {
+	case "add":
+		if existing != nil {
+			fmt.Fprint(rw, "NOT_STORED\r\n")
+			return nil
+		}
+	case "replace":
+		if existing == nil {
+			fmt.Fprint(rw, "NOT_STORED\r\n")
+			return nil
+		}
+	case "cas":
+		if existing == nil {
+			fmt.Fprint(rw, "NOT_FOUND\r\n")
+			return nil
+		}
+		casID, _ := strconv.ParseUint(fields[5], 10, 64)
+		if existing.cas != casID {
+			fmt.Fprint(rw, "EXISTS\r\n")
+			return nil
+		}
+	}
+
+	fs.nextCAS++
+	fs.items[key] = &fakeItem{
+		value:     data[:size],
+		flags:     uint32(flags),
+		expiresAt: expiresAt(exptime),
+		cas:       fs.nextCAS,
+	}
+	fmt.Fprint(rw, "STORED\r\n")
+	return nil
+}
+
+func (fs *fakeServer) handleDelete(rw *bufio.ReadWriter, args []string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if len(args) == 0 || fs.lookup(args[0]) == nil {
+		fmt.Fprint(rw, "NOT_FOUND\r\n")
+		return
+	}
+	delete(fs.items, args[0])
+	fmt.Fprint(rw, "DELETED\r\n")
+}
+
+func (fs *fakeServer) handleTouch(rw *bufio.ReadWriter, args []string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if len(args) < 2 {
+		fmt.Fprint(rw, "ERROR\r\n")
+		return
+	}
+	item := fs.lookup(args[0])
+	if item == nil {
+		fmt.Fprint(rw, "NOT_FOUND\r\n")
+		return
+	}
+	exptime, _ := strconv.ParseInt(args[1], 10, 64)
+	item.expiresAt = expiresAt(exptime)
+	fmt.Fprint(rw, "TOUCHED\r\n")
+}
+
+// expiresAt 按memcached规则解析过期时间：0永不过期，负数立即过期，
+// 不超过30天为相对秒数，否则为绝对Unix时间戳
+func expiresAt(exptime int64) time.Time {
+	switch {
+	case exptime == 0:
+		return time.Time{}
+	case exptime < 0:
+		return time.Now()
+	case time.Duration(exptime)*time.Second <= maxRelativeExpiration:
+		return time.Now().Add(time.Duration(exptime) * time.Second)
+	default:
+		return time.Unix(exptime, 0)
+	}
+}
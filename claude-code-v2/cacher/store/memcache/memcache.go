@@ -0,0 +1,281 @@
+package memcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"go-cache/cacher/store"
+)
+
+const (
+	// maxKeyLength memcached允许的最大键长度
+	maxKeyLength = 250
+	// maxRelativeExpiration 超过30天的过期时间会被memcached当作绝对Unix时间戳
+	maxRelativeExpiration = 30 * 24 * time.Hour
+	// hashedKeyPrefix 哈希后的键前缀
+	hashedKeyPrefix = "sha256:"
+)
+
+// Options Memcache Store配置
+type Options struct {
+	// Codec 值编解码器，为nil时使用store.DefaultCodec
+	Codec store.Codec
+
+	// DeleteCorrupt 启用后MGet中无法解码的键在返回*store.BatchError的同时被删除
+	DeleteCorrupt bool
+}
+
+// Store Memcached实现的Store接口
+type Store struct {
+	client *memcache.Client
+	codec  store.Codec
+
+	deleteCorrupt bool
+}
+
+// NewStore 创建新的Memcache Store实例
+// opts: 配置项，可以为nil使用默认配置
+func NewStore(client *memcache.Client, opts *Options) *Store {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+
+	return &Store{
+		client:        client,
+		codec:         o.Codec,
+		deleteCorrupt: o.DeleteCorrupt,
+	}
+}
+
+// Get 从memcached获取单个值
+func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	item, err := s.client.Get(encodeKey(key))
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("memcache get error: %w", err)
+	}
+
+	if err := s.codec.Unmarshal(item.Value, dst); err != nil {
+		return false, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return true, nil
+}
+
+// MGet 使用get-multi批量获取值到map中
+func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 验证dstMap是map指针
+	dstMapValue := reflect.ValueOf(dstMap)
+	if dstMapValue.Kind() != reflect.Ptr || dstMapValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("dstMap must be a pointer to map")
+	}
+
+	mapValue := dstMapValue.Elem()
+	mapType := mapValue.Type()
+	valueType := mapType.Elem()
+
+	// 如果map为nil，初始化它
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapType))
+	}
+
+	items, originals, err := s.getMulti(keys)
+	if err != nil {
+		return err
+	}
+
+	// 无法解码的键跳过并记录，不影响其他键
+	batchErr := &store.BatchError{}
+	for encodedKey, item := range items {
+		key := originals[encodedKey]
+
+		// 创建值类型的新实例并解码
+		valuePtr := reflect.New(valueType)
+		if err := s.codec.Unmarshal(item.Value, valuePtr.Interface()); err != nil {
+			batchErr.Add(key, fmt.Errorf("failed to decode value: %w", err))
+			continue
+		}
+
+		mapValue.SetMapIndex(reflect.ValueOf(key), valuePtr.Elem())
+	}
+
+	if s.deleteCorrupt && len(batchErr.Errors) > 0 {
+		// 删除失败不影响返回结果，下次读取时会再次尝试
+		_, _ = s.Del(ctx, batchErr.Keys()...)
+	}
+	return batchErr.Err()
+}
+
+// Exists 使用get-multi批量检查键存在性
+func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	items, originals, err := s.getMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		result[key] = false
+	}
+	for encodedKey := range items {
+		result[originals[encodedKey]] = true
+	}
+
+	return result, nil
+}
+
+// MSet 批量设置键值对，支持TTL
+func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	exp := expiration(ttl, time.Now())
+	for key, value := range items {
+		payload, err := s.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value for key %s: %w", key, err)
+		}
+
+		err = s.client.Set(&memcache.Item{
+			Key:        encodeKey(key),
+			Value:      payload,
+			Expiration: exp,
+		})
+		if err != nil {
+			return fmt.Errorf("memcache set error for key %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// Del 删除指定键，memcached没有批量删除命令，逐个删除
+func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var deletedCount int64
+	for _, key := range keys {
+		err := s.client.Delete(encodeKey(key))
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			continue
+		}
+		if err != nil {
+			return deletedCount, fmt.Errorf("memcache delete error for key %s: %w", key, err)
+		}
+		deletedCount++
+	}
+
+	return deletedCount, nil
+}
+
+// Close 不会关闭客户端，客户端由调用方创建并可能被其他代码共享，由调用方负责关闭
+func (s *Store) Close() error {
+	return nil
+}
+
+// Ping 检查所有服务器是否可用
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.client.Ping(); err != nil {
+		return fmt.Errorf("memcache ping error: %w", err)
+	}
+	return nil
+}
+
+// Flush 使用flush_all清空所有服务器
+func (s *Store) Flush(ctx context.Context) error {
+	if err := s.client.FlushAll(); err != nil {
+		return fmt.Errorf("memcache flush_all error: %w", err)
+	}
+	return nil
+}
+
+// getMulti 编码键后执行get-multi，返回结果及编码键到原始键的映射
+func (s *Store) getMulti(keys []string) (map[string]*memcache.Item, map[string]string, error) {
+	originals := make(map[string]string, len(keys))
+	encodedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		encodedKey := encodeKey(key)
+		if _, ok := originals[encodedKey]; !ok {
+			encodedKeys = append(encodedKeys, encodedKey)
+		}
+		originals[encodedKey] = key
+	}
+
+	items, err := s.client.GetMulti(encodedKeys)
+	if err != nil {
+		return nil, nil, fmt.Errorf("memcache get multi error: %w", err)
+	}
+
+	return items, originals, nil
+}
+
+// encodeKey 将不满足memcached键规则的键(空、超过250字节、含空白或控制字符)替换为其哈希
+func encodeKey(key string) string {
+	if isLegalKey(key) {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hashedKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// isLegalKey 检查键是否可以直接用于memcached文本协议
+// 以哈希前缀开头的原始键也需要哈希，避免与哈希后的键冲突
+func isLegalKey(key string) bool {
+	if len(key) == 0 || len(key) > maxKeyLength {
+		return false
+	}
+	if len(key) >= len(hashedKeyPrefix) && key[:len(hashedKeyPrefix)] == hashedKeyPrefix {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// expiration 将TTL转换为memcached的过期时间参数
+// 不超过30天时使用相对秒数(不足1秒按1秒)，超过30天时必须使用绝对Unix时间戳
+func expiration(ttl time.Duration, now time.Time) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	if ttl > maxRelativeExpiration {
+		return int32(now.Add(ttl).Unix())
+	}
+	seconds := int32((ttl + time.Second - 1) / time.Second)
+	return seconds
+}
+
+// 确保Store实现了store.Store、store.Closer、store.Pinger和store.Flusher接口
+var (
+	_ store.Store   = (*Store)(nil)
+	_ store.Closer  = (*Store)(nil)
+	_ store.Pinger  = (*Store)(nil)
+	_ store.Flusher = (*Store)(nil)
+)
@@ -0,0 +1,90 @@
+package memcache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+)
+
+// newTestStore 创建连接到模拟服务器的Memcache Store
+func newTestStore(t *testing.T) *Store {
+	server := newFakeServer(t)
+	client := memcache.New(server.Addr())
+	t.Cleanup(func() { client.Close() })
+	return NewStore(client, nil)
+}
+
+func TestMemcacheStore(t *testing.T) {
+	// 创建Memcache Store
+	memcacheStore := newTestStore(t)
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, memcacheStore)
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+
+	assert.NoError(t, memcacheStore.Ping(context.Background()))
+
+	// Close不会关闭调用方的客户端
+	require.NoError(t, memcacheStore.Close())
+	assert.NoError(t, memcacheStore.Ping(context.Background()))
+}
+
+func TestMemcacheStoreIllegalKeys(t *testing.T) {
+	ctx := context.Background()
+	memcacheStore := newTestStore(t)
+
+	longKey := strings.Repeat("k", 300)
+	items := map[string]interface{}{
+		"key with spaces": "spaces",
+		longKey:           "long",
+		"normal":          "normal",
+	}
+	require.NoError(t, memcacheStore.MSet(ctx, items, 0))
+
+	resultMap := make(map[string]string)
+	require.NoError(t, memcacheStore.MGet(ctx, []string{"key with spaces", longKey, "normal"}, &resultMap))
+	assert.Equal(t, map[string]string{"key with spaces": "spaces", longKey: "long", "normal": "normal"}, resultMap)
+
+	exists, err := memcacheStore.Exists(ctx, []string{"key with spaces", longKey, "missing key"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"key with spaces": true, longKey: true, "missing key": false}, exists)
+}
+
+func TestMemcacheStoreLongTTL(t *testing.T) {
+	ctx := context.Background()
+	memcacheStore := newTestStore(t)
+
+	// 超过30天的TTL若按相对秒数发送，会被服务器当作1970年的时间戳而立即过期
+	require.NoError(t, memcacheStore.MSet(ctx, map[string]interface{}{"long_ttl": "value"}, 60*24*time.Hour))
+
+	var result string
+	found, err := memcacheStore.Get(ctx, "long_ttl", &result)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", result)
+}
+
+func TestExpiration(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	assert.Equal(t, int32(0), expiration(0, now))
+	assert.Equal(t, int32(1), expiration(100*time.Millisecond, now))
+	assert.Equal(t, int32(60), expiration(time.Minute, now))
+	assert.Equal(t, int32(30*24*3600), expiration(30*24*time.Hour, now))
+	assert.Equal(t, int32(now.Add(31*24*time.Hour).Unix()), expiration(31*24*time.Hour, now))
+}
+
+func TestEncodeKey(t *testing.T) {
+	assert.Equal(t, "user:1", encodeKey("user:1"))
+	assert.True(t, strings.HasPrefix(encodeKey(""), hashedKeyPrefix))
+	assert.True(t, strings.HasPrefix(encodeKey("a b"), hashedKeyPrefix))
+	assert.True(t, strings.HasPrefix(encodeKey(strings.Repeat("k", 251)), hashedKeyPrefix))
+	assert.NotEqual(t, encodeKey("a b"), encodeKey(encodeKey("a b")))
+}
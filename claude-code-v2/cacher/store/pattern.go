@@ -0,0 +1,100 @@
+package store
+
+// MatchPattern 判断key是否匹配Redis风格的glob模式，供不支持模式匹配的后端实现Scanner
+// 支持*(任意字节序列)、?(任意单个字节)、[abc]/[^abc]/[a-z]字符集合以及\转义，
+// 与Redis一致，*可以匹配包括/在内的任意字节；空模式匹配所有键
+func MatchPattern(pattern, key string) bool {
+	if pattern == "" {
+		return true
+	}
+	return matchPattern(pattern, key)
+}
+
+// matchPattern 使用迭代的星号回溯匹配，只记录最近一个*的位置，时间复杂度为O(len(p)*len(s))
+func matchPattern(p, s string) bool {
+	pi, si := 0, 0
+	// starP 最近一个*在模式中的位置，starS 该*当前匹配到的key位置
+	starP, starS := -1, 0
+	for si < len(s) {
+		if pi < len(p) && p[pi] == '*' {
+			starP, starS = pi, si
+			pi++
+			continue
+		}
+		if pi < len(p) {
+			if matched, next := matchOne(p, pi, s[si]); matched {
+				pi, si = next, si+1
+				continue
+			}
+		}
+		// 不匹配时让最近的*多匹配一个字节
+		if starP >= 0 {
+			starS++
+			pi, si = starP+1, starS
+			continue
+		}
+		return false
+	}
+
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// matchOne 匹配模式中pi处的单个元素(非*)与字节c
+// 返回: 是否匹配, 该元素之后的模式位置
+func matchOne(p string, pi int, c byte) (bool, int) {
+	switch p[pi] {
+	case '?':
+		return true, pi + 1
+	case '[':
+		matched, rest := matchClass(p[pi+1:], c)
+		return matched, len(p) - len(rest)
+	case '\\':
+		if pi+1 < len(p) {
+			return p[pi+1] == c, pi + 2
+		}
+	}
+	return p[pi] == c, pi + 1
+}
+
+// matchClass 匹配[...]字符集合，p为[之后的部分
+// 返回: c是否属于该集合, ]之后剩余的模式
+func matchClass(p string, c byte) (bool, string) {
+	negate := len(p) > 0 && p[0] == '^'
+	if negate {
+		p = p[1:]
+	}
+
+	matched := false
+	for len(p) > 0 && p[0] != ']' {
+		switch {
+		case p[0] == '\\' && len(p) >= 2:
+			if p[1] == c {
+				matched = true
+			}
+			p = p[2:]
+		case len(p) >= 3 && p[1] == '-' && p[2] != ']':
+			lo, hi := p[0], p[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			p = p[3:]
+		default:
+			if p[0] == c {
+				matched = true
+			}
+			p = p[1:]
+		}
+	}
+	// 与Redis一致，缺少]时视为集合延伸到模式末尾
+	if len(p) > 0 {
+		p = p[1:]
+	}
+
+	return matched != negate, p
+}
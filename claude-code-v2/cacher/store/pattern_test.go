@@ -0,0 +1,57 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"", "anything", true},
+		{"*", "", true},
+		{"user:*", "user:1", true},
+		{"user:*", "user:1/profile", true},
+		{"user:*", "order:1", false},
+		{"*:profile", "user:1:profile", true},
+		{"user:?", "user:1", true},
+		{"user:?", "user:12", false},
+		{"user:[12]", "user:2", true},
+		{"user:[12]", "user:3", false},
+		{"user:[^12]", "user:3", true},
+		{"user:[^12]", "user:1", false},
+		{"user:[0-9]*", "user:42", true},
+		{"user:[9-0]", "user:5", true},
+		{"user:[a-c]", "user:d", false},
+		{`user:\*`, "user:*", true},
+		{`user:\*`, "user:1", false},
+		{`[\]]`, "]", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"exact", "exact", true},
+		{"exact", "exactly", false},
+		{"*?", "", false},
+		{"**x", "x", true},
+		{"user:*[0-9]", "user:ab3", true},
+		{"user:*[0-9]", "user:3ab", false},
+		{`a\`, `a\`, true},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, MatchPattern(tc.pattern, tc.key), "pattern %q key %q", tc.pattern, tc.key)
+	}
+
+	// 多个*匹配长键时不会指数级回溯
+	pattern := strings.Repeat("*a", 30) + "b"
+	key := strings.Repeat("a", 1000)
+	start := time.Now()
+	assert.False(t, MatchPattern(pattern, key))
+	assert.True(t, MatchPattern(pattern, key+"b"))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// msetScript 原子地写入多个键，ARGV依次为每个键的值和以毫秒为单位的TTL(0表示永不过期)
+// Redis不会回滚脚本中已执行的写入，因此先校验所有参数，参数有误时不写入任何键
+var msetScript = redis.NewScript(`
+local n = #KEYS
+if #ARGV ~= n * 2 then
+	return redis.error_reply('ERR wrong number of arguments for mset script')
+end
+for i = 1, n do
+	local ttl = tonumber(ARGV[i * 2])
+	if ttl == nil or ttl < 0 or ttl % 1 ~= 0 then
+		return redis.error_reply('ERR invalid ttl for key ' .. KEYS[i])
+	end
+end
+for i = 1, n do
+	local ttl = tonumber(ARGV[i * 2])
+	if ttl > 0 then
+		redis.call('SET', KEYS[i], ARGV[i * 2 - 1], 'PX', ttl)
+	else
+		redis.call('SET', KEYS[i], ARGV[i * 2 - 1])
+	end
+end
+return n
+`)
+
+// atomicSet 使用msetScript原子地写入所有键，不按BatchSize拆分
+// 所有值先完成序列化，任一值序列化失败时不写入任何键。
+// Cluster和Ring下脚本只能访问同一哈希槽(分片)的键，因此按groupKeys分组执行，只保证组内原子
+// ttl: 返回每个键的过期时间
+func (s *Store) atomicSet(ctx context.Context, items map[string]interface{}, ttl func(key string) time.Duration) error {
+	keys := make([]string, 0, len(items))
+	values := make(map[string]string, len(items))
+	for key, value := range items {
+		jsonData, err := s.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON for key %s: %w", key, err)
+		}
+		keys = append(keys, key)
+		values[key] = string(jsonData)
+	}
+
+	groups := groupKeys(s.topology, keys)
+	args := make([][]interface{}, len(groups))
+	for i, group := range groups {
+		args[i] = make([]interface{}, 0, len(group.keys)*2)
+		for _, key := range group.keys {
+			args[i] = append(args[i], values[key], ttlMillis(ttl(key)))
+		}
+	}
+
+	// 通常脚本已缓存在服务端，直接以EVALSHA执行；脚本缓存被清空(NOSCRIPT)时加载后重试一次
+	err := s.evalGroups(ctx, groups, args)
+	if redis.HasErrorPrefix(err, "NOSCRIPT") {
+		if err := s.loadScript(ctx, msetScript); err != nil {
+			return fmt.Errorf("redis script load error: %w", err)
+		}
+		err = s.evalGroups(ctx, groups, args)
+	}
+	if err != nil {
+		return fmt.Errorf("redis atomic mset error: %w", err)
+	}
+	return nil
+}
+
+// evalGroups 在一个pipeline中为每组键以EVALSHA执行msetScript
+func (s *Store) evalGroups(ctx context.Context, groups []keyGroup, args [][]interface{}) error {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.Cmd, len(groups))
+	for i, group := range groups {
+		cmds[i] = msetScript.EvalSha(ctx, route(s.topology, s.client, pipe, group.keys[0]), group.keys, args[i]...)
+	}
+
+	// 单个命令的错误在下面逐个检查
+	_, _ = pipe.Exec(ctx)
+
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package redis
+
+import (
+	"context"
+
+	"go-cache/cacher/store"
+)
+
+// defaultBatchSize 单条多键命令或单个pipeline默认最多包含的键数量
+const defaultBatchSize = 1000
+
+// defaultParallelism 默认同时执行的批次数量
+const defaultParallelism = 4
+
+// forChunks 将[0, n)按BatchSize拆分，以最多Parallelism个并发执行fn
+func (s *Store) forChunks(ctx context.Context, n int, fn func(ctx context.Context, start, end int) error) error {
+	return store.ForChunks(ctx, n, s.batchSize, s.parallelism, fn)
+}
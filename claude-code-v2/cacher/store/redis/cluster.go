@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// clusterSlots Redis Cluster的哈希槽数量
+const clusterSlots = 16384
+
+// topology 客户端的部署拓扑
+type topology int
+
+const (
+	// topologySingle 单节点(含Sentinel故障转移客户端)，多键命令可以直接发送
+	topologySingle topology = iota
+	// topologyCluster Redis Cluster，多键命令必须落在同一个哈希槽
+	topologyCluster
+	// topologyRing Redis Ring，多键命令必须落在同一个分片
+	topologyRing
+)
+
+// detectTopology 根据客户端类型判断部署拓扑
+// redis.NewUniversalClient返回的客户端同样适用
+func detectTopology(client redis.Cmdable) topology {
+	switch client.(type) {
+	case *redis.ClusterClient:
+		return topologyCluster
+	case *redis.Ring:
+		return topologyRing
+	default:
+		return topologySingle
+	}
+}
+
+// route 返回执行首个键为firstKey的命令时应使用的客户端
+// go-redis的Ring在pipeline中按首个键选择分片，首个键为空字符串时会随机选择分片，
+// 因此Ring模式下空键的命令绕过pipeline直接发送
+func route(topo topology, client redis.Cmdable, pipe redis.Pipeliner, firstKey string) redis.Cmdable {
+	if topo == topologyRing && firstKey == "" {
+		return client
+	}
+	return pipe
+}
+
+// keyGroup 可以在一条多键命令中发送的一组键
+type keyGroup struct {
+	keys []string
+	// indexes 每个键在原始键列表中的位置，用于合并结果
+	indexes []int
+}
+
+// groupKeys 按拓扑将键拆分为可以安全执行多键命令的分组
+// Cluster按哈希槽分组；Ring无法得知分片映射，按哈希标签分组，
+// 同一哈希标签的键一定位于同一分片，没有哈希标签的键单独成组
+func groupKeys(topo topology, keys []string) []keyGroup {
+	if topo == topologySingle {
+		indexes := make([]int, len(keys))
+		for i := range keys {
+			indexes[i] = i
+		}
+		return []keyGroup{{keys: keys, indexes: indexes}}
+	}
+
+	groups := make([]keyGroup, 0)
+	positions := make(map[string]int)
+	for i, key := range keys {
+		var groupKey string
+		if topo == topologyCluster {
+			groupKey = strconv.Itoa(hashSlot(key))
+		} else {
+			tag, ok := hashTag(key)
+			if !ok {
+				tag = "\x00" + key
+			}
+			groupKey = tag
+		}
+
+		pos, ok := positions[groupKey]
+		if !ok {
+			pos = len(groups)
+			positions[groupKey] = pos
+			groups = append(groups, keyGroup{})
+		}
+		groups[pos].keys = append(groups[pos].keys, key)
+		groups[pos].indexes = append(groups[pos].indexes, i)
+	}
+
+	return groups
+}
+
+// hashTag 提取键中的哈希标签，即第一个"{"与其后第一个"}"之间的非空内容
+func hashTag(key string) (string, bool) {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return "", false
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return "", false
+	}
+	return key[start+1 : start+1+end], true
+}
+
+// hashSlot 计算键在Redis Cluster中的哈希槽，支持哈希标签
+func hashSlot(key string) int {
+	if tag, ok := hashTag(key); ok {
+		key = tag
+	}
+	return int(crc16(key)) % clusterSlots
+}
+
+// crc16 Redis Cluster使用的CRC16-CCITT(XMODEM)校验
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
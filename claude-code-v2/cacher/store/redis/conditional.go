@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// setHashIfScript 按条件以哈希写入结构体，ARGV依次为条件(NX或XX)、以毫秒为单位的TTL(0表示永不过期)和HSET的字段值对
+// 条件满足时先DEL再HSET，与msetHashes一致，不残留旧字段
+var setHashIfScript = redis.NewScript(`
+local exists = redis.call('EXISTS', KEYS[1]) == 1
+if (ARGV[1] == 'NX' and exists) or (ARGV[1] == 'XX' and not exists) then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], unpack(ARGV, 3))
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+// MSetNX 使用pipeline对每个键执行SET NX PX
+// 哈希布局下结构体值使用Lua脚本在键不存在时以哈希写入
+func (s *Store) MSetNX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	return s.msetIf(ctx, items, func(string) time.Duration { return ttl }, "NX")
+}
+
+// MSetXX 使用pipeline对每个键执行SET XX PX
+// 哈希布局下结构体值使用Lua脚本在键已存在时以哈希写入
+func (s *Store) MSetXX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	return s.msetIf(ctx, items, func(string) time.Duration { return ttl }, "XX")
+}
+
+// MSetEntriesNX 使用pipeline对每个键执行SET NX PX，每个键使用各自的过期时间
+func (s *Store) MSetEntriesNX(ctx context.Context, entries []store.Entry) (map[string]bool, error) {
+	// 同一个键出现多次时以最后一个为准
+	items := make(map[string]interface{}, len(entries))
+	ttls := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		items[entry.Key] = entry.Value
+		ttls[entry.Key] = entry.TTL
+	}
+	return s.msetIf(ctx, items, func(key string) time.Duration { return ttls[key] }, "NX")
+}
+
+// msetIf 在pipeline中对每个键执行有条件的写入，键较多时分批执行
+// ttl: 返回每个键的过期时间
+// mode: NX(键不存在时写入)或XX(键已存在时写入)
+func (s *Store) msetIf(ctx context.Context, items map[string]interface{}, ttl func(key string) time.Duration, mode string) (map[string]bool, error) {
+	result := make(map[string]bool, len(items))
+
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	var hashItems map[string]reflect.Value
+	if s.hashLayout {
+		items, hashItems = s.splitHashItems(items)
+	}
+
+	// 所有值先完成编码，任一值编码失败时不写入任何键
+	keys := make([]string, 0, len(items)+len(hashItems))
+	args := make([][]interface{}, 0, cap(keys))
+	for key, value := range items {
+		jsonData, err := s.codec.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON for key %s: %w", key, err)
+		}
+		keys = append(keys, key)
+		args = append(args, []interface{}{string(jsonData)})
+	}
+	plainCount := len(keys)
+	for key, value := range hashItems {
+		fields, err := s.encodeHash(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode hash for key %s: %w", key, err)
+		}
+		keys = append(keys, key)
+		args = append(args, append([]interface{}{mode, ttlMillis(ttl(key))}, fields...))
+	}
+
+	if len(hashItems) > 0 {
+		if err := s.loadScript(ctx, setHashIfScript); err != nil {
+			return nil, fmt.Errorf("redis script load error: %w", err)
+		}
+	}
+
+	written := make([]bool, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]redis.Cmder, 0, end-start)
+		for i := start; i < end; i++ {
+			c := route(s.topology, s.client, pipe, keys[i])
+			switch {
+			case i >= plainCount:
+				cmds = append(cmds, setHashIfScript.EvalSha(ctx, c, []string{keys[i]}, args[i]...))
+			case mode == "NX":
+				cmds = append(cmds, c.SetNX(ctx, keys[i], args[i][0], ttl(keys[i])))
+			default:
+				cmds = append(cmds, c.SetXX(ctx, keys[i], args[i][0], ttl(keys[i])))
+			}
+		}
+
+		// 单个命令的错误在下面逐个检查
+		_, _ = pipe.Exec(ctx)
+
+		for j, cmd := range cmds {
+			i := start + j
+			switch cmd := cmd.(type) {
+			case *redis.BoolCmd:
+				ok, err := cmd.Result()
+				if err != nil {
+					return fmt.Errorf("redis pipeline set error for key %s: %w", keys[i], err)
+				}
+				written[i] = ok
+			case *redis.Cmd:
+				n, err := cmd.Int64()
+				if err != nil {
+					return fmt.Errorf("redis hset error for key %s: %w", keys[i], err)
+				}
+				written[i] = n == 1
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	writtenKeys := make([]string, 0, len(keys))
+	for i, key := range keys {
+		result[key] = written[i]
+		if written[i] {
+			writtenKeys = append(writtenKeys, key)
+		}
+	}
+	s.notifyWrites(ctx, store.WatchSet, writtenKeys)
+
+	return result, nil
+}
+
+// 确保Store实现了store.ConditionalSetter接口
+var _ store.ConditionalSetter = (*Store)(nil)
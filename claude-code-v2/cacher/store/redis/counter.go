@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// incrByScript 原子地执行INCRBY，并在键没有过期时间时设置PEXPIRE
+var incrByScript = redis.NewScript(`
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl > 0 and redis.call('PTTL', KEYS[1]) == -1 then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return value
+`)
+
+// IncrBy 使用Lua脚本原子地增加计数器并设置过期时间
+func (s *Store) IncrBy(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	value, err := incrByScript.Run(ctx, s.client, []string{key}, delta, ttlMillis(ttl)).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("redis incrby error: %w", err)
+	}
+	s.notifyWrites(ctx, store.WatchSet, []string{key})
+	return value, nil
+}
+
+// MIncrBy 使用pipeline对每个键执行IncrBy脚本，键较多时分批执行
+// 不同键可能位于不同的哈希槽，因此每个键单独执行脚本，整体不保证原子性
+func (s *Store) MIncrBy(ctx context.Context, deltas map[string]int64, ttl time.Duration) (map[string]int64, error) {
+	result := make(map[string]int64, len(deltas))
+
+	if len(deltas) == 0 {
+		return result, nil
+	}
+
+	// 确保脚本已加载，pipeline中使用EVALSHA
+	if err := s.loadScript(ctx, incrByScript); err != nil {
+		return nil, fmt.Errorf("redis script load error: %w", err)
+	}
+
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+
+	values := make([]int64, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]*redis.Cmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, incrByScript.EvalSha(ctx, route(s.topology, s.client, pipe, key), []string{key}, deltas[key], ttlMillis(ttl)))
+		}
+
+		// 单个命令的错误在下面逐个检查
+		_, _ = pipe.Exec(ctx)
+
+		for j, cmd := range cmds {
+			value, err := cmd.Int64()
+			if err != nil {
+				return fmt.Errorf("redis incrby error for key %s: %w", keys[start+j], err)
+			}
+			values[start+j] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		result[key] = values[i]
+	}
+	s.notifyWrites(ctx, store.WatchSet, keys)
+
+	return result, nil
+}
+
+// loadScript 在所有节点上加载脚本，Cluster和Ring的每个节点都需要单独加载
+func (s *Store) loadScript(ctx context.Context, script *redis.Script) error {
+	if s.topology == topologySingle {
+		return script.Load(ctx, s.client).Err()
+	}
+
+	nodes, err := s.masterNodes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := script.Load(ctx, node).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ttlMillis 将TTL转换为毫秒，不足1毫秒按1毫秒处理，0表示不设置过期时间
+func ttlMillis(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int64((ttl + time.Millisecond - 1) / time.Millisecond)
+}
+
+// 确保Store实现了store.Counter接口
+var _ store.Counter = (*Store)(nil)
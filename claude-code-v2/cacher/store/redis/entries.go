@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// MSetEntries 使用pipeline对每个键执行SET PX，每个键使用各自的过期时间，键较多时分批执行
+// 哈希布局下结构体值仍以哈希写入，并单独设置过期时间
+func (s *Store) MSetEntries(ctx context.Context, entries []store.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// 同一个键出现多次时以最后一个为准
+	items := make(map[string]interface{}, len(entries))
+	ttls := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		items[entry.Key] = entry.Value
+		ttls[entry.Key] = entry.TTL
+	}
+
+	if err := s.msetEntries(ctx, items, ttls); err != nil {
+		return err
+	}
+	s.notifyWrites(ctx, store.WatchSet, itemKeys(items))
+	return nil
+}
+
+// msetEntries 执行MSetEntries的写入，ttls为每个键的过期时间
+func (s *Store) msetEntries(ctx context.Context, items map[string]interface{}, ttls map[string]time.Duration) error {
+	if s.hashLayout {
+		var hashItems map[string]reflect.Value
+		items, hashItems = s.splitHashItems(items)
+		if err := s.msetHashes(ctx, hashItems, func(key string) time.Duration { return ttls[key] }); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+	}
+
+	if s.atomicWrites {
+		return s.atomicSet(ctx, items, func(key string) time.Duration { return ttls[key] })
+	}
+	return s.pipelineSet(ctx, items, func(key string) time.Duration { return ttls[key] })
+}
+
+// 确保Store实现了store.EntrySetter接口
+var _ store.EntrySetter = (*Store)(nil)
@@ -0,0 +1,236 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// EventSource OnEvict和Watch事件的来源
+type EventSource int
+
+const (
+	// EventSourceAuto 首次使用时用CONFIG GET检查notify-keyspace-events，
+	// OnEvict在启用了键事件(如"Exeg"或"EA")时订阅键事件通知，Watch在启用了键空间事件(如"Kg$xe"或"KA")时订阅键空间通知，
+	// 否则分别使用进程内模拟和PublishWrites频道
+	EventSourceAuto EventSource = iota
+	// EventSourceKeyspace 订阅键空间通知，服务端需要已启用，OnEvict订阅失败时退回进程内模拟
+	EventSourceKeyspace
+	// EventSourceEmulated 不使用键空间通知：OnEvict只报告本Store删除的键，Watch订阅PublishWrites频道，
+	// 适用于测试或无法修改服务端配置的场景
+	EventSourceEmulated
+)
+
+// eventsSetupTimeout 首次调用OnEvict时检查配置和建立订阅的超时时间
+const eventsSetupTimeout = 5 * time.Second
+
+// keyeventReasons 订阅的键事件及其对应的原因，UNLINK同样产生del事件
+var keyeventReasons = map[string]store.EvictReason{
+	"expired": store.EvictExpired,
+	"evicted": store.EvictCapacity,
+	"del":     store.EvictDeleted,
+}
+
+// patternSubscriber 支持PSUBSCRIBE的客户端
+type patternSubscriber interface {
+	PSubscribe(ctx context.Context, patterns ...string) *redis.PubSub
+}
+
+// evictEvents OnEvict的状态，在首次注册回调时确定事件来源
+type evictEvents struct {
+	handlers store.EvictHandlers
+	once     sync.Once
+
+	// emulated 为true时由Store在删除键后自行产生事件
+	emulated atomic.Bool
+
+	mutex  sync.Mutex
+	cancel context.CancelFunc
+}
+
+// stop 停止键空间通知的订阅
+func (e *evictEvents) stop() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+// OnEvict 注册键离开缓存时的回调，Value始终为nil
+// 订阅键空间通知时报告任意客户端造成的过期(Expired)、maxmemory淘汰(Capacity)和DEL/UNLINK(Deleted)，
+// 回调在后台goroutine中依次执行；HashLayout下覆盖结构体会先DEL，同样报告为Deleted。
+// 进程内模拟时只报告本Store的Del以及DeleteCorrupt删除的键(Deleted)，回调在删除后同步执行。
+// Redis无法区分覆盖和新写入，不报告Replaced。Cluster和Ring订阅调用时的每个主节点或分片
+func (s *Store) OnEvict(fn func(store.Event)) {
+	s.events.handlers.Add(fn)
+	s.events.once.Do(s.startEvents)
+}
+
+// startEvents 确定事件来源，需要时订阅键空间通知
+func (s *Store) startEvents() {
+	ctx, cancel := context.WithTimeout(context.Background(), eventsSetupTimeout)
+	defer cancel()
+
+	source := s.eventSource
+	if source == EventSourceAuto {
+		source = EventSourceEmulated
+		if s.keyspaceEventsEnabled(ctx) {
+			source = EventSourceKeyspace
+		}
+	}
+
+	if source == EventSourceKeyspace {
+		if err := s.subscribeKeyevents(ctx); err == nil {
+			return
+		}
+	}
+	s.events.emulated.Store(true)
+}
+
+// eventNodes 返回需要检查配置和订阅的节点，单节点时为客户端本身
+func (s *Store) eventNodes(ctx context.Context) ([]redis.Cmdable, error) {
+	if s.topology == topologySingle {
+		return []redis.Cmdable{s.client}, nil
+	}
+
+	masters, err := s.masterNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]redis.Cmdable, len(masters))
+	for i, node := range masters {
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// keyspaceEventsEnabled 检查每个节点是否都启用了所需的键事件通知
+func (s *Store) keyspaceEventsEnabled(ctx context.Context) bool {
+	nodes, err := s.eventNodes(ctx)
+	if err != nil || len(nodes) == 0 {
+		return false
+	}
+
+	for _, node := range nodes {
+		config, err := node.ConfigGet(ctx, "notify-keyspace-events").Result()
+		if err != nil || !keyeventFlagsEnabled(config["notify-keyspace-events"]) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyeventFlagsEnabled 判断notify-keyspace-events的取值是否包含键事件(E)以及过期(x)、淘汰(e)和通用命令(g)
+func keyeventFlagsEnabled(flags string) bool {
+	if !strings.Contains(flags, "E") {
+		return false
+	}
+	return strings.Contains(flags, "A") ||
+		(strings.Contains(flags, "x") && strings.Contains(flags, "e") && strings.Contains(flags, "g"))
+}
+
+// subscribeKeyevents 在每个节点上订阅键事件频道，订阅全部建立后返回
+func (s *Store) subscribeKeyevents(ctx context.Context) error {
+	nodes, err := s.eventNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("redis keyspace events error: %w", err)
+	}
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	for _, node := range nodes {
+		client, ok := node.(patternSubscriber)
+		if !ok {
+			cancel()
+			return fmt.Errorf("redis client %T does not support subscribe", node)
+		}
+
+		// 频道名包含数据库编号，使用模式订阅匹配客户端所在的数据库
+		db := 0
+		if c, ok := node.(*redis.Client); ok {
+			db = c.Options().DB
+		}
+		pubsub := client.PSubscribe(ctx, fmt.Sprintf("__keyevent@%d__:*", db))
+		if _, err := pubsub.Receive(ctx); err != nil {
+			_ = pubsub.Close()
+			cancel()
+			return fmt.Errorf("redis psubscribe error: %w", err)
+		}
+
+		go s.listenKeyevents(listenCtx, pubsub)
+	}
+
+	s.events.mutex.Lock()
+	s.events.cancel = cancel
+	s.events.mutex.Unlock()
+	return nil
+}
+
+// listenKeyevents 将键事件转换为Event交给回调，直到ctx被取消
+func (s *Store) listenKeyevents(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			_, event, _ := strings.Cut(msg.Channel, "__:")
+			reason, ok := keyeventReasons[event]
+			if !ok {
+				continue
+			}
+			s.events.handlers.Emit(store.Event{Key: msg.Payload, Reason: reason})
+		}
+	}
+}
+
+// delTracked 逐个DEL，从而得知哪些键被删除，用于进程内模拟OnEvict事件和PublishWrites
+// 返回: 被删除的键, 错误信息
+func (s *Store) delTracked(ctx context.Context, keys []string) ([]string, error) {
+	deleted := make([]bool, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]*redis.IntCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, route(s.topology, s.client, pipe, key).Del(ctx, key))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+
+		for i, cmd := range cmds {
+			count, err := cmd.Result()
+			if err != nil {
+				return err
+			}
+			deleted[start+i] = count > 0
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deletedKeys := make([]string, 0, len(keys))
+	for i, key := range keys {
+		if deleted[i] {
+			deletedKeys = append(deletedKeys, key)
+		}
+	}
+	return deletedKeys, nil
+}
+
+// 确保Store实现了store.EvictNotifier接口
+var _ store.EvictNotifier = (*Store)(nil)
@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// TTL 使用pipeline批量执行PTTL读取剩余过期时间，键较多时分批执行
+func (s *Store) TTL(ctx context.Context, keys []string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	ttls := make([]time.Duration, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]*redis.DurationCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, route(s.topology, s.client, pipe, key).PTTL(ctx, key))
+		}
+
+		// 单个命令的错误在下面逐个检查
+		_, _ = pipe.Exec(ctx)
+
+		for j, cmd := range cmds {
+			ttl, err := cmd.Result()
+			if err != nil {
+				return fmt.Errorf("redis pttl error for key %s: %w", keys[start+j], err)
+			}
+			ttls[start+j] = ttl
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ttl := range ttls {
+		// PTTL返回-2表示键不存在，-1表示永不过期
+		switch ttl {
+		case -2:
+			continue
+		case -1:
+			result[keys[i]] = store.NoTTL
+		default:
+			result[keys[i]] = ttl
+		}
+	}
+
+	return result, nil
+}
+
+// Expire 使用pipeline批量执行PEXPIRE
+func (s *Store) Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		return 0, fmt.Errorf("ttl must be positive")
+	}
+
+	return s.pipelineBool(ctx, keys, "pexpire", func(c redis.Cmdable, key string) *redis.BoolCmd {
+		return c.PExpire(ctx, key, ttl)
+	})
+}
+
+// Persist 使用pipeline批量执行PERSIST
+func (s *Store) Persist(ctx context.Context, keys []string) (int64, error) {
+	return s.pipelineBool(ctx, keys, "persist", func(c redis.Cmdable, key string) *redis.BoolCmd {
+		return c.Persist(ctx, key)
+	})
+}
+
+// pipelineBool 对每个键执行一条返回布尔值的命令，键较多时分批执行，返回结果为true的数量
+func (s *Store) pipelineBool(ctx context.Context, keys []string, name string, cmd func(c redis.Cmdable, key string) *redis.BoolCmd) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var count atomic.Int64
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]*redis.BoolCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, cmd(route(s.topology, s.client, pipe, key), key))
+		}
+
+		// 单个命令的错误在下面逐个检查
+		_, _ = pipe.Exec(ctx)
+
+		for j, c := range cmds {
+			ok, err := c.Result()
+			if err != nil {
+				return fmt.Errorf("redis %s error for key %s: %w", name, keys[start+j], err)
+			}
+			if ok {
+				count.Add(1)
+			}
+		}
+		return nil
+	})
+
+	return count.Load(), err
+}
+
+// 确保Store实现了store.Expirer接口
+var _ store.Expirer = (*Store)(nil)
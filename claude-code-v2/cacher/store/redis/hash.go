@@ -0,0 +1,322 @@
+package redis
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// hashFieldsCache 缓存每个结构体类型的字段映射
+	hashFieldsCache sync.Map
+)
+
+// updateFieldsScript 仅在键存在时更新哈希字段，避免创建没有TTL的残缺哈希
+var updateFieldsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// hashField 结构体字段与哈希字段的对应关系
+type hashField struct {
+	name  string
+	index int
+}
+
+// hashFields 返回结构体类型的导出字段，哈希字段名优先使用json标签
+func hashFields(t reflect.Type) []hashField {
+	if cached, ok := hashFieldsCache.Load(t); ok {
+		return cached.([]hashField)
+	}
+
+	fields := make([]hashField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields = append(fields, hashField{name: name, index: i})
+	}
+
+	hashFieldsCache.Store(t, fields)
+	return fields
+}
+
+// isHashType 判断类型是否以哈希布局存储
+// 只有包含导出字段且没有自定义序列化方式(如time.Time)的结构体才会拆分为哈希
+func isHashType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	if t.Implements(jsonMarshalerType) || ptr.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || ptr.Implements(textMarshalerType) {
+		return false
+	}
+	return len(hashFields(t)) > 0
+}
+
+// isWrongType 判断错误是否为WRONGTYPE，即键的实际类型与命令不匹配
+func isWrongType(err error) bool {
+	var redisErr redis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "WRONGTYPE")
+}
+
+// hashTarget 启用哈希布局且dst指向可哈希的结构体时，返回该结构体
+func (s *Store) hashTarget(dst interface{}) (reflect.Value, bool) {
+	if !s.hashLayout {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || !isHashType(v.Elem().Type()) {
+		return reflect.Value{}, false
+	}
+	return v.Elem(), true
+}
+
+// splitHashItems 将待写入的值拆分为普通值和以哈希存储的结构体值
+func (s *Store) splitHashItems(items map[string]interface{}) (map[string]interface{}, map[string]reflect.Value) {
+	plain := make(map[string]interface{}, len(items))
+	hashes := make(map[string]reflect.Value)
+
+	for key, value := range items {
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.IsValid() && isHashType(v.Type()) {
+			hashes[key] = v
+		} else {
+			plain[key] = value
+		}
+	}
+
+	return plain, hashes
+}
+
+// encodeHash 将结构体编码为HSET参数，每个字段值单独编码
+func (s *Store) encodeHash(v reflect.Value) ([]interface{}, error) {
+	fields := hashFields(v.Type())
+	args := make([]interface{}, 0, len(fields)*2)
+	for _, field := range fields {
+		data, err := s.codec.Marshal(v.Field(field.index).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %s: %w", field.name, err)
+		}
+		args = append(args, field.name, string(data))
+	}
+	return args, nil
+}
+
+// decodeHash 将HGETALL的结果解码到结构体，dst必须可寻址
+func (s *Store) decodeHash(values map[string]string, dst reflect.Value) error {
+	dst.Set(reflect.Zero(dst.Type()))
+	for _, field := range hashFields(dst.Type()) {
+		raw, ok := values[field.name]
+		if !ok {
+			continue
+		}
+		if err := s.codec.Unmarshal([]byte(raw), dst.Field(field.index).Addr().Interface()); err != nil {
+			return fmt.Errorf("failed to decode field %s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// getHash 使用HGETALL读取结构体
+func (s *Store) getHash(ctx context.Context, key string, dst reflect.Value) (bool, error) {
+	values, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis hgetall error: %w", err)
+	}
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	if err := s.decodeHash(values, dst); err != nil {
+		return false, fmt.Errorf("failed to decode hash: %w", err)
+	}
+
+	return true, nil
+}
+
+// mgetHashes 使用pipeline批量HGETALL并写入mapValue
+// 无法解码的键记录到batchErr，返回以字符串形式存储、需要继续用MGET读取的键
+func (s *Store) mgetHashes(ctx context.Context, keys []string, mapValue reflect.Value, batchErr *store.BatchError) ([]string, error) {
+	// 键较多时分批执行pipeline，各批次只写入cmds中自己的区间
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		for i := start; i < end; i++ {
+			cmds[i] = route(s.topology, s.client, pipe, keys[i]).HGetAll(ctx, keys[i])
+		}
+		// 单个命令的错误在下面逐个检查
+		_, _ = pipe.Exec(ctx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	valueType := mapValue.Type().Elem()
+	remaining := make([]string, 0)
+	for i, cmd := range cmds {
+		values, err := cmd.Result()
+		if isWrongType(err) {
+			remaining = append(remaining, keys[i])
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hgetall error for key %s: %w", keys[i], err)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		value := reflect.New(valueType).Elem()
+		if err := s.decodeHash(values, value); err != nil {
+			batchErr.Add(keys[i], fmt.Errorf("failed to decode hash: %w", err))
+			continue
+		}
+		mapValue.SetMapIndex(reflect.ValueOf(keys[i]), value)
+	}
+
+	return remaining, nil
+}
+
+// msetHashes 以事务pipeline写入结构体哈希
+// 每个键先DEL再HSET，保证不残留旧字段，ttl返回键的过期时间，大于0时追加PEXPIRE
+func (s *Store) msetHashes(ctx context.Context, items map[string]reflect.Value, ttl func(key string) time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	// 键较多时分批执行，每批一个事务pipeline
+	return s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.TxPipeline()
+		cmds := make([]redis.Cmder, 0, (end-start)*3)
+		for _, key := range keys[start:end] {
+			args, err := s.encodeHash(items[key])
+			if err != nil {
+				return fmt.Errorf("failed to encode hash for key %s: %w", key, err)
+			}
+
+			c := route(s.topology, s.client, pipe, key)
+			cmds = append(cmds, c.Del(ctx, key), c.HSet(ctx, key, args...))
+			if ttl := ttl(key); ttl > 0 {
+				cmds = append(cmds, c.PExpire(ctx, key, ttl))
+			}
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("redis hset error: %w", err)
+		}
+		for _, cmd := range cmds {
+			if err := cmd.Err(); err != nil {
+				return fmt.Errorf("redis hset error: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetFields 使用HMGET只读取哈希中的部分字段到结构体
+// dst: 目标结构体指针，未请求的字段保持原值
+// fields: 哈希字段名(json标签名或字段名)
+// 返回: 是否读取到任一字段, 错误信息
+func (s *Store) GetFields(ctx context.Context, key string, dst interface{}, fields ...string) (bool, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || !isHashType(v.Elem().Type()) {
+		return false, fmt.Errorf("dst must be a pointer to struct")
+	}
+	if len(fields) == 0 {
+		return false, nil
+	}
+
+	target := v.Elem()
+	indexes := make(map[string]int)
+	for _, field := range hashFields(target.Type()) {
+		indexes[field.name] = field.index
+	}
+
+	vals, err := s.client.HMGet(ctx, key, fields...).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis hmget error: %w", err)
+	}
+
+	found := false
+	for i, val := range vals {
+		if val == nil {
+			continue
+		}
+		index, ok := indexes[fields[i]]
+		if !ok {
+			return false, fmt.Errorf("unknown field %s", fields[i])
+		}
+		if err := s.codec.Unmarshal([]byte(val.(string)), target.Field(index).Addr().Interface()); err != nil {
+			return false, fmt.Errorf("failed to decode field %s: %w", fields[i], err)
+		}
+		found = true
+	}
+
+	return found, nil
+}
+
+// UpdateFields 使用HSET只更新哈希中的指定字段，键原有的TTL保持不变
+// fields: 哈希字段名(json标签名或字段名)到新值的映射，值会用Codec编码
+// 返回: 键是否存在并被更新, 错误信息
+func (s *Store) UpdateFields(ctx context.Context, key string, fields map[string]interface{}) (bool, error) {
+	if len(fields) == 0 {
+		return false, nil
+	}
+
+	args := make([]interface{}, 0, len(fields)*2)
+	for name, value := range fields {
+		data, err := s.codec.Marshal(value)
+		if err != nil {
+			return false, fmt.Errorf("failed to encode field %s: %w", name, err)
+		}
+		args = append(args, name, string(data))
+	}
+
+	updated, err := updateFieldsScript.Run(ctx, s.client, []string{key}, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("redis update fields error: %w", err)
+	}
+	if updated == 1 {
+		s.notifyWrites(ctx, store.WatchSet, []string{key})
+	}
+
+	return updated == 1, nil
+}
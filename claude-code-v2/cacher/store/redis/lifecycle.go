@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"go-cache/cacher/store"
+)
+
+// Close 停止键空间通知的订阅，不会关闭Redis客户端
+// 客户端由调用方创建并可能被其他代码共享，由调用方负责关闭
+func (s *Store) Close() error {
+	s.events.stop()
+	return nil
+}
+
+// Ping 检查连接，Cluster和Ring会检查每个主节点或分片
+func (s *Store) Ping(ctx context.Context) error {
+	if s.topology == topologySingle {
+		if err := s.client.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("redis ping error: %w", err)
+		}
+		return nil
+	}
+
+	nodes, err := s.masterNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("redis ping error: %w", err)
+	}
+	for _, node := range nodes {
+		if err := node.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("redis ping error on %s: %w", node.Options().Addr, err)
+		}
+	}
+	return nil
+}
+
+// Flush 使用FLUSHDB清空当前数据库，Cluster和Ring会清空每个主节点或分片
+func (s *Store) Flush(ctx context.Context) error {
+	if s.topology == topologySingle {
+		if err := s.client.FlushDB(ctx).Err(); err != nil {
+			return fmt.Errorf("redis flushdb error: %w", err)
+		}
+		return nil
+	}
+
+	nodes, err := s.masterNodes(ctx)
+	if err != nil {
+		return fmt.Errorf("redis flushdb error: %w", err)
+	}
+	for _, node := range nodes {
+		if err := node.FlushDB(ctx).Err(); err != nil {
+			return fmt.Errorf("redis flushdb error on %s: %w", node.Options().Addr, err)
+		}
+	}
+	return nil
+}
+
+// 确保Store实现了store.Closer、store.Pinger和store.Flusher接口
+var (
+	_ store.Closer  = (*Store)(nil)
+	_ store.Pinger  = (*Store)(nil)
+	_ store.Flusher = (*Store)(nil)
+)
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// subscriber 支持SUBSCRIBE的客户端，*redis.Client、*redis.ClusterClient和*redis.Ring都实现了该接口
+type subscriber interface {
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+// Publish 使用PUBLISH发布消息
+func (s *Store) Publish(ctx context.Context, channel string, message string) error {
+	if err := s.client.Publish(ctx, channel, message).Err(); err != nil {
+		return fmt.Errorf("redis publish error: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 使用SUBSCRIBE订阅channel，连接断开时go-redis会自动重连并重新订阅
+func (s *Store) Subscribe(ctx context.Context, channel string, handler func(message string)) error {
+	client, ok := s.client.(subscriber)
+	if !ok {
+		return fmt.Errorf("redis client %T does not support subscribe", s.client)
+	}
+
+	pubsub := client.Subscribe(ctx, channel)
+
+	// 等待订阅确认，保证返回后发布的消息不会丢失
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return fmt.Errorf("redis subscribe error: %w", err)
+	}
+
+	messages := pubsub.Channel()
+	go func() {
+		defer pubsub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				handler(msg.Payload)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// 确保Store实现了store.PubSub接口
+var _ store.PubSub = (*Store)(nil)
@@ -2,29 +2,126 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"go-cache/cacher/store"
 )
 
+// Options Redis Store配置
+type Options struct {
+	// Codec 值编解码器，为nil时使用store.DefaultCodec
+	Codec store.Codec
+
+	// HashLayout 启用后结构体值以Redis哈希存储，每个导出字段对应一个哈希字段，
+	// 字段值分别用Codec编码，从而支持字段级的部分读取和更新
+	HashLayout bool
+
+	// DeleteCorrupt 启用后MGet中无法解码的键在返回*store.BatchError的同时被删除
+	// 解码失败也可能是dstMap的值类型与写入时不一致，只有确定键的类型固定时才应启用
+	DeleteCorrupt bool
+
+	// BatchSize 单条多键命令(MGET、MSET、DEL)或单个pipeline最多包含的键数量，
+	// 超出时拆分为多个批次，避免单条命令长时间阻塞Redis，0使用默认值1000
+	BatchSize int
+
+	// Parallelism 拆分后同时执行的批次数量，0使用默认值4
+	Parallelism int
+
+	// AtomicWrites 启用后MSet和MSetEntries使用Lua脚本一次写入所有键及其TTL，
+	// 要么全部写入，要么都不写入；写入不再按BatchSize拆分。
+	// Cluster和Ring下只保证同一哈希槽(分片)内的键原子写入，
+	// HashLayout下以哈希存储的结构体值仍单独以事务写入
+	AtomicWrites bool
+
+	// EventSource OnEvict和Watch事件的来源，默认根据服务端是否启用键空间通知自动选择
+	EventSource EventSource
+
+	// PublishWrites 启用后，键空间通知不可用(或EventSource为EventSourceEmulated)时，
+	// 每次写入和删除成功后额外向WatchChannel发布消息，供各进程的Watch订阅；
+	// 所有写入方都需要启用，过期无法通过这种方式报告
+	PublishWrites bool
+
+	// WatchChannel PublishWrites使用的频道，为空时使用"go-cache:watch"
+	WatchChannel string
+}
+
 // Store Redis实现的Store接口
+// 传入*redis.ClusterClient或*redis.Ring时，多键命令会按哈希槽或分片拆分后并行执行
 type Store struct {
-	client redis.Cmdable
+	client   redis.Cmdable
+	topology topology
+	codec    store.Codec
+
+	hashLayout    bool
+	deleteCorrupt bool
+
+	batchSize    int
+	parallelism  int
+	atomicWrites bool
+
+	eventSource EventSource
+	events      evictEvents
+
+	publishWrites bool
+	watchChannel  string
+	watch         watchState
 }
 
 // NewStore 创建新的Redis Store实例
 func NewStore(client redis.Cmdable) *Store {
+	return NewStoreWithOptions(client, nil)
+}
+
+// NewStoreWithOptions 使用指定配置创建Redis Store实例
+// opts: 配置项，可以为nil使用默认配置
+func NewStoreWithOptions(client redis.Cmdable, opts *Options) *Store {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaultBatchSize
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = defaultParallelism
+	}
+	if o.WatchChannel == "" {
+		o.WatchChannel = defaultWatchChannel
+	}
+
 	return &Store{
-		client: client,
+		client:        client,
+		topology:      detectTopology(client),
+		codec:         o.Codec,
+		hashLayout:    o.HashLayout,
+		deleteCorrupt: o.DeleteCorrupt,
+		batchSize:     o.BatchSize,
+		parallelism:   o.Parallelism,
+		atomicWrites:  o.AtomicWrites,
+		eventSource:   o.EventSource,
+		publishWrites: o.PublishWrites,
+		watchChannel:  o.WatchChannel,
 	}
 }
 
 // Get 从Redis获取单个值
 func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	// 哈希布局下结构体使用HGETALL读取
+	if target, ok := s.hashTarget(dst); ok {
+		found, err := s.getHash(ctx, key, target)
+		if !isWrongType(err) {
+			return found, err
+		}
+		// 键以字符串形式存储(如启用哈希布局之前写入)，按普通方式读取
+	}
+
 	val, err := s.client.Get(ctx, key).Result()
 	if err == redis.Nil {
 		return false, nil
@@ -34,7 +131,7 @@ func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, err
 	}
 
 	// 反序列化JSON到目标对象
-	if err := json.Unmarshal([]byte(val), dst); err != nil {
+	if err := s.codec.Unmarshal([]byte(val), dst); err != nil {
 		return false, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 
@@ -62,8 +159,23 @@ func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) err
 		mapValue.Set(reflect.MakeMap(mapType))
 	}
 
+	// 无法解码的键跳过并记录，不影响其他键
+	batchErr := &store.BatchError{}
+
+	// 哈希布局下结构体使用pipeline批量HGETALL，以字符串存储的键继续走MGET
+	if s.hashLayout && isHashType(valueType) {
+		var err error
+		keys, err = s.mgetHashes(ctx, keys, mapValue, batchErr)
+		if err != nil {
+			return fmt.Errorf("redis mget error: %w", err)
+		}
+		if len(keys) == 0 {
+			return s.dropCorrupt(ctx, batchErr)
+		}
+	}
+
 	// 执行Redis MGET
-	vals, err := s.client.MGet(ctx, keys...).Result()
+	vals, err := s.mget(ctx, keys)
 	if err != nil {
 		return fmt.Errorf("redis mget error: %w", err)
 	}
@@ -76,10 +188,11 @@ func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) err
 
 		// 创建值类型的新实例
 		valuePtr := reflect.New(valueType)
-		
+
 		// 反序列化JSON
-		if err := json.Unmarshal([]byte(val.(string)), valuePtr.Interface()); err != nil {
-			return fmt.Errorf("failed to unmarshal JSON for key %s: %w", keys[i], err)
+		if err := s.codec.Unmarshal([]byte(val.(string)), valuePtr.Interface()); err != nil {
+			batchErr.Add(keys[i], fmt.Errorf("failed to unmarshal JSON: %w", err))
+			continue
 		}
 
 		// 设置到map中
@@ -87,37 +200,54 @@ func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) err
 		mapValue.SetMapIndex(keyValue, valuePtr.Elem())
 	}
 
-	return nil
+	return s.dropCorrupt(ctx, batchErr)
+}
+
+// dropCorrupt 启用DeleteCorrupt时删除无法解码的键，返回batchErr.Err()
+func (s *Store) dropCorrupt(ctx context.Context, batchErr *store.BatchError) error {
+	if s.deleteCorrupt && len(batchErr.Errors) > 0 {
+		// 删除失败不影响返回结果，下次读取时会再次尝试
+		_, _ = s.del(ctx, batchErr.Keys())
+	}
+	return batchErr.Err()
 }
 
 // Exists 批量检查键存在性
 func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
 	result := make(map[string]bool)
-	
+
 	if len(keys) == 0 {
 		return result, nil
 	}
 
-	// 使用pipeline批量检查存在性
-	pipe := s.client.Pipeline()
-	cmds := make([]*redis.IntCmd, len(keys))
-	
-	for i, key := range keys {
-		cmds[i] = pipe.Exists(ctx, key)
-	}
-	
-	_, err := pipe.Exec(ctx)
+	// 使用pipeline批量检查存在性，键较多时分批执行
+	exists := make([]bool, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]*redis.IntCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			cmds = append(cmds, route(s.topology, s.client, pipe, key).Exists(ctx, key))
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("redis exists error: %w", err)
+		}
+		for i, cmd := range cmds {
+			count, err := cmd.Result()
+			if err != nil {
+				return fmt.Errorf("redis exists error for key %s: %w", keys[start+i], err)
+			}
+			exists[start+i] = count > 0
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("redis exists error: %w", err)
+		return nil, err
 	}
-	
+
 	// 收集结果
-	for i, cmd := range cmds {
-		exists, err := cmd.Result()
-		if err != nil {
-			return nil, fmt.Errorf("redis exists error for key %s: %w", keys[i], err)
-		}
-		result[keys[i]] = exists > 0
+	for i, key := range keys {
+		result[key] = exists[i]
 	}
 
 	return result, nil
@@ -129,20 +259,46 @@ func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time
 		return nil
 	}
 
+	if err := s.msetItems(ctx, items, ttl); err != nil {
+		return err
+	}
+	s.notifyWrites(ctx, store.WatchSet, itemKeys(items))
+	return nil
+}
+
+// msetItems 执行MSet的写入
+func (s *Store) msetItems(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	// 哈希布局下结构体值单独以哈希写入
+	if s.hashLayout {
+		var hashItems map[string]reflect.Value
+		items, hashItems = s.splitHashItems(items)
+		if err := s.msetHashes(ctx, hashItems, func(string) time.Duration { return ttl }); err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+	}
+
+	// 原子写入时使用Lua脚本
+	if s.atomicWrites {
+		return s.atomicSet(ctx, items, func(string) time.Duration { return ttl })
+	}
+
 	// 如果没有TTL，使用MSET批量设置
 	if ttl == 0 {
 		// 准备键值对切片
 		args := make([]interface{}, 0, len(items)*2)
 		for key, value := range items {
 			// 序列化值为JSON
-			jsonData, err := json.Marshal(value)
+			jsonData, err := s.codec.Marshal(value)
 			if err != nil {
 				return fmt.Errorf("failed to marshal JSON for key %s: %w", key, err)
 			}
 			args = append(args, key, string(jsonData))
 		}
 
-		err := s.client.MSet(ctx, args...).Err()
+		err := s.mset(ctx, args)
 		if err != nil {
 			return fmt.Errorf("redis mset error: %w", err)
 		}
@@ -150,23 +306,39 @@ func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time
 	}
 
 	// 有TTL时使用pipeline批量设置
-	pipe := s.client.Pipeline()
-	
-	for key, value := range items {
-		// 序列化值为JSON
-		jsonData, err := json.Marshal(value)
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON for key %s: %w", key, err)
-		}
-		pipe.Set(ctx, key, string(jsonData), ttl)
-	}
-	
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("redis pipeline set error: %w", err)
+	return s.pipelineSet(ctx, items, func(string) time.Duration { return ttl })
+}
+
+// pipelineSet 在pipeline中对每个键执行SET PX，键较多时分批执行
+// ttl: 返回每个键的过期时间
+func (s *Store) pipelineSet(ctx context.Context, items map[string]interface{}, ttl func(key string) time.Duration) error {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
 	}
 
-	return nil
+	return s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		pipe := s.client.Pipeline()
+		cmds := make([]*redis.StatusCmd, 0, end-start)
+		for _, key := range keys[start:end] {
+			// 序列化值为JSON
+			jsonData, err := s.codec.Marshal(items[key])
+			if err != nil {
+				return fmt.Errorf("failed to marshal JSON for key %s: %w", key, err)
+			}
+			cmds = append(cmds, route(s.topology, s.client, pipe, key).Set(ctx, key, string(jsonData), ttl(key)))
+		}
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("redis pipeline set error: %w", err)
+		}
+		for _, cmd := range cmds {
+			if err := cmd.Err(); err != nil {
+				return fmt.Errorf("redis pipeline set error: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
 // Del 删除指定键
@@ -175,7 +347,7 @@ func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
 		return 0, nil
 	}
 
-	deletedCount, err := s.client.Del(ctx, keys...).Result()
+	deletedCount, err := s.del(ctx, keys)
 	if err != nil {
 		return 0, fmt.Errorf("redis del error: %w", err)
 	}
@@ -183,5 +355,154 @@ func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
 	return deletedCount, nil
 }
 
+// mget 按BatchSize分批执行MGET，返回与keys一一对应的结果
+func (s *Store) mget(ctx context.Context, keys []string) ([]interface{}, error) {
+	vals := make([]interface{}, len(keys))
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		chunkVals, err := s.mgetChunk(ctx, keys[start:end])
+		if err != nil {
+			return err
+		}
+		copy(vals[start:end], chunkVals)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// mgetChunk 执行一批MGET
+// 集群模式下按分组在一个pipeline中发送多条MGET，go-redis会按节点并行执行
+func (s *Store) mgetChunk(ctx context.Context, keys []string) ([]interface{}, error) {
+	if s.topology == topologySingle {
+		return s.client.MGet(ctx, keys...).Result()
+	}
+
+	groups := groupKeys(s.topology, keys)
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(groups))
+	for i, group := range groups {
+		cmds[i] = route(s.topology, s.client, pipe, group.keys[0]).MGet(ctx, group.keys...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	vals := make([]interface{}, len(keys))
+	for i, group := range groups {
+		groupVals, err := cmds[i].Result()
+		if err != nil {
+			return nil, err
+		}
+		for j, index := range group.indexes {
+			vals[index] = groupVals[j]
+		}
+	}
+
+	return vals, nil
+}
+
+// mset 按BatchSize分批执行MSET，args为交替排列的键和值
+func (s *Store) mset(ctx context.Context, args []interface{}) error {
+	return s.forChunks(ctx, len(args)/2, func(ctx context.Context, start, end int) error {
+		return s.msetChunk(ctx, args[start*2:end*2])
+	})
+}
+
+// msetChunk 执行一批MSET
+func (s *Store) msetChunk(ctx context.Context, args []interface{}) error {
+	if s.topology == topologySingle {
+		return s.client.MSet(ctx, args...).Err()
+	}
+
+	keys := make([]string, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		keys = append(keys, args[i].(string))
+	}
+
+	groups := groupKeys(s.topology, keys)
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StatusCmd, len(groups))
+	for i, group := range groups {
+		groupArgs := make([]interface{}, 0, len(group.keys)*2)
+		for _, index := range group.indexes {
+			groupArgs = append(groupArgs, args[index*2], args[index*2+1])
+		}
+		cmds[i] = route(s.topology, s.client, pipe, group.keys[0]).MSet(ctx, groupArgs...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// del 按BatchSize分批执行DEL，返回删除的键数量
+// 进程内模拟OnEvict事件或需要发布写入时逐个删除，以便得知哪些键被删除
+func (s *Store) del(ctx context.Context, keys []string) (int64, error) {
+	emulated := s.events.emulated.Load()
+	if emulated || s.publishingWrites() {
+		deleted, err := s.delTracked(ctx, keys)
+		if err != nil {
+			return 0, err
+		}
+		if emulated {
+			for _, key := range deleted {
+				s.events.handlers.Emit(store.Event{Key: key, Reason: store.EvictDeleted})
+			}
+		}
+		s.notifyWrites(ctx, store.WatchDelete, deleted)
+		return int64(len(deleted)), nil
+	}
+
+	var deletedCount atomic.Int64
+	err := s.forChunks(ctx, len(keys), func(ctx context.Context, start, end int) error {
+		count, err := s.delChunk(ctx, keys[start:end])
+		if err != nil {
+			return err
+		}
+		deletedCount.Add(count)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deletedCount.Load(), nil
+}
+
+// delChunk 执行一批DEL
+func (s *Store) delChunk(ctx context.Context, keys []string) (int64, error) {
+	if s.topology == topologySingle {
+		return s.client.Del(ctx, keys...).Result()
+	}
+
+	groups := groupKeys(s.topology, keys)
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(groups))
+	for i, group := range groups {
+		cmds[i] = route(s.topology, s.client, pipe, group.keys[0]).Del(ctx, group.keys...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var deletedCount int64
+	for _, cmd := range cmds {
+		count, err := cmd.Result()
+		if err != nil {
+			return 0, err
+		}
+		deletedCount += count
+	}
+
+	return deletedCount, nil
+}
+
 // 确保Store实现了store.Store接口
 var _ store.Store = (*Store)(nil)
@@ -1,10 +1,15 @@
 package redis
 
 import (
+	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go-cache/cacher/store"
 )
@@ -27,4 +32,878 @@ func TestRedisStore(t *testing.T) {
 	// 运行通用测试套件
 	testHelper := store.NewTestHelper(t, redisStore)
 	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+
+	// miniredis不支持CONFIG GET，OnEvict退回进程内模拟
+	testHelper.TestEvictNotifier()
+
+	// 连接可用时Ping成功，服务器关闭后失败
+	ctx := context.Background()
+	assert.NoError(t, redisStore.Ping(ctx))
+
+	// Close不会关闭调用方的客户端
+	require.NoError(t, redisStore.Close())
+	assert.NoError(t, client.Ping(ctx).Err())
+
+	mr.Close()
+	assert.Error(t, redisStore.Ping(ctx))
+}
+
+func TestRedisStoreRing(t *testing.T) {
+	ctx := context.Background()
+
+	// 启动多个miniredis作为Ring分片
+	addrs := make(map[string]string)
+	shards := make([]*miniredis.Miniredis, 3)
+	for i := range shards {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+		shards[i] = mr
+		addrs[fmt.Sprintf("shard%d", i)] = mr.Addr()
+	}
+
+	ring := redis.NewRing(&redis.RingOptions{Addrs: addrs})
+	defer ring.Close()
+
+	ringStore := NewStore(ring)
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, ringStore)
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+	testHelper.TestEvictNotifier()
+	assert.NoError(t, ringStore.Ping(ctx))
+
+	// 键应分布到多个分片，MGet仍能取回全部值
+	items := make(map[string]interface{})
+	keys := make([]string, 0, 50)
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("ring_key_%d", i)
+		items[key] = i
+		keys = append(keys, key)
+	}
+	require.NoError(t, ringStore.MSet(ctx, items, 0))
+
+	usedShards := 0
+	for _, mr := range shards {
+		if len(mr.Keys()) > 0 {
+			usedShards++
+		}
+	}
+	assert.Greater(t, usedShards, 1)
+
+	resultMap := make(map[string]int)
+	require.NoError(t, ringStore.MGet(ctx, keys, &resultMap))
+	assert.Len(t, resultMap, 50)
+	assert.Equal(t, 42, resultMap["ring_key_42"])
+
+	deleted, err := ringStore.Del(ctx, keys...)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), deleted)
+
+	// 相同哈希标签的键位于同一分片
+	require.NoError(t, ringStore.MSet(ctx, map[string]interface{}{"{user:1}:name": "alice", "{user:1}:age": 30}, 0))
+	for _, mr := range shards {
+		if mr.Exists("{user:1}:name") {
+			assert.True(t, mr.Exists("{user:1}:age"))
+		}
+	}
+}
+
+func TestHashSlot(t *testing.T) {
+	// Redis Cluster规范中的示例值
+	assert.Equal(t, 12739, hashSlot("123456789"))
+	assert.Equal(t, 12182, hashSlot("foo"))
+	assert.Equal(t, hashSlot("{user1000}.following"), hashSlot("{user1000}.followers"))
+	assert.Equal(t, hashSlot("foo{}{bar}"), crc16Slot("foo{}{bar}"))
+	assert.Equal(t, hashSlot("foo{bar}{zap}"), hashSlot("bar"))
+}
+
+func TestGroupKeys(t *testing.T) {
+	keys := []string{"{a}1", "b", "{a}2", "c"}
+
+	single := groupKeys(topologySingle, keys)
+	assert.Len(t, single, 1)
+
+	ring := groupKeys(topologyRing, keys)
+	assert.Len(t, ring, 3)
+	assert.Equal(t, []string{"{a}1", "{a}2"}, ring[0].keys)
+	assert.Equal(t, []int{0, 2}, ring[0].indexes)
+
+	cluster := groupKeys(topologyCluster, keys)
+	total := 0
+	for _, group := range cluster {
+		for _, key := range group.keys {
+			assert.Equal(t, hashSlot(group.keys[0]), hashSlot(key))
+		}
+		total += len(group.keys)
+	}
+	assert.Equal(t, len(keys), total)
+}
+
+// crc16Slot 不处理哈希标签，直接计算整个键的哈希槽
+func crc16Slot(key string) int {
+	return int(crc16(key)) % clusterSlots
+}
+
+func TestRedisStoreHashLayout(t *testing.T) {
+	ctx := context.Background()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	hashStore := NewStoreWithOptions(client, &Options{HashLayout: true})
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, hashStore)
+	testHelper.RunAllTests()
+
+	type Profile struct {
+		Name    string   `json:"name"`
+		Age     int      `json:"age"`
+		Tags    []string `json:"tags"`
+		Ignored string   `json:"-"`
+	}
+
+	alice := Profile{Name: "Alice", Age: 30, Tags: []string{"admin"}}
+	require.NoError(t, hashStore.MSet(ctx, map[string]interface{}{"profile:1": alice, "profile:2": &Profile{Name: "Bob"}}, time.Hour))
+
+	// 结构体以哈希存储，每个字段单独编码
+	assert.Equal(t, "hash", mr.Type("profile:1"))
+	assert.Equal(t, `"Alice"`, mr.HGet("profile:1", "name"))
+	assert.Equal(t, "30", mr.HGet("profile:1", "age"))
+	fields, err := mr.HKeys("profile:1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"name", "age", "tags"}, fields)
+
+	var profile Profile
+	found, err := hashStore.Get(ctx, "profile:1", &profile)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, alice, profile)
+
+	profiles := make(map[string]Profile)
+	require.NoError(t, hashStore.MGet(ctx, []string{"profile:1", "profile:2", "missing"}, &profiles))
+	assert.Len(t, profiles, 2)
+	assert.Equal(t, "Bob", profiles["profile:2"].Name)
+
+	// 部分读取
+	var partial Profile
+	found, err = hashStore.GetFields(ctx, "profile:1", &partial, "age")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, Profile{Age: 30}, partial)
+
+	// 部分更新保留TTL
+	ttlBefore := mr.TTL("profile:1")
+	updated, err := hashStore.UpdateFields(ctx, "profile:1", map[string]interface{}{"age": 31})
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, ttlBefore, mr.TTL("profile:1"))
+
+	found, err = hashStore.Get(ctx, "profile:1", &profile)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 31, profile.Age)
+	assert.Equal(t, "Alice", profile.Name)
+
+	// 不存在的键不会被创建
+	updated, err = hashStore.UpdateFields(ctx, "missing", map[string]interface{}{"age": 1})
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.False(t, mr.Exists("missing"))
+
+	// 以字符串存储的结构体仍然可以读取
+	require.NoError(t, NewStore(client).MSet(ctx, map[string]interface{}{"profile:3": Profile{Name: "Carol"}}, 0))
+	found, err = hashStore.Get(ctx, "profile:3", &profile)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Carol", profile.Name)
+
+	profiles = make(map[string]Profile)
+	require.NoError(t, hashStore.MGet(ctx, []string{"profile:1", "profile:3"}, &profiles))
+	assert.Len(t, profiles, 2)
+	assert.Equal(t, "Carol", profiles["profile:3"].Name)
+
+	// 按键设置过期时间的结构体同样以哈希存储
+	require.NoError(t, hashStore.MSetEntries(ctx, []store.Entry{
+		{Key: "profile:4", Value: Profile{Name: "Dave"}, TTL: time.Minute},
+		{Key: "profile:5", Value: Profile{Name: "Eve"}},
+	}))
+	assert.Equal(t, "hash", mr.Type("profile:4"))
+	assert.Equal(t, time.Minute, mr.TTL("profile:4"))
+	assert.Equal(t, time.Duration(0), mr.TTL("profile:5"))
+
+	// 有条件的写入同样以哈希存储结构体，之后可以部分更新
+	written, err := hashStore.MSetNX(ctx, map[string]interface{}{"profile:6": Profile{Name: "Frank", Age: 40}, "profile:1": alice}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"profile:6": true, "profile:1": false}, written)
+	assert.Equal(t, "hash", mr.Type("profile:6"))
+	assert.Equal(t, time.Minute, mr.TTL("profile:6"))
+	updated, err = hashStore.UpdateFields(ctx, "profile:6", map[string]interface{}{"age": 41})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	written, err = hashStore.MSetXX(ctx, map[string]interface{}{"profile:3": &Profile{Name: "Carol", Age: 25}, "profile:7": Profile{Name: "Gina"}}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"profile:3": true, "profile:7": false}, written)
+	assert.Equal(t, "hash", mr.Type("profile:3"))
+	assert.False(t, mr.Exists("profile:7"))
+	partial = Profile{}
+	found, err = hashStore.GetFields(ctx, "profile:3", &partial, "age")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 25, partial.Age)
+
+	// time.Time等自定义序列化的结构体不拆分为哈希
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, hashStore.MSet(ctx, map[string]interface{}{"time": now}, 0))
+	assert.Equal(t, "string", mr.Type("time"))
+}
+
+func TestRedisStoreMGetCorrupt(t *testing.T) {
+	ctx := context.Background()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	require.NoError(t, mr.Set("good", `"value"`))
+	require.NoError(t, mr.Set("corrupt", `{not json`))
+
+	// 默认只跳过并报告无法解码的键
+	redisStore := NewStore(client)
+	values := make(map[string]string)
+	err = redisStore.MGet(ctx, []string{"good", "corrupt"}, &values)
+	var batchErr *store.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []string{"corrupt"}, batchErr.Keys())
+	assert.Equal(t, map[string]string{"good": "value"}, values)
+	assert.True(t, mr.Exists("corrupt"))
+
+	// 启用DeleteCorrupt时同时删除
+	redisStore = NewStoreWithOptions(client, &Options{DeleteCorrupt: true})
+	values = make(map[string]string)
+	err = redisStore.MGet(ctx, []string{"good", "corrupt"}, &values)
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, map[string]string{"good": "value"}, values)
+	assert.False(t, mr.Exists("corrupt"))
+	assert.True(t, mr.Exists("good"))
+}
+
+func TestRedisStoreChunking(t *testing.T) {
+	ctx := context.Background()
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	chunkedStore := NewStoreWithOptions(client, &Options{BatchSize: 3, Parallelism: 2})
+	store.NewTestHelper(t, chunkedStore).RunAllTests()
+
+	keys := make([]string, 10)
+	items := make(map[string]interface{}, len(keys))
+	for i := range keys {
+		keys[i] = fmt.Sprintf("chunk:%d", i)
+		items[keys[i]] = i
+	}
+
+	// 10个键拆分为4条MSET、MGET和DEL
+	count := mr.CommandCount()
+	require.NoError(t, chunkedStore.MSet(ctx, items, 0))
+	assert.Equal(t, 4, mr.CommandCount()-count)
+
+	count = mr.CommandCount()
+	values := make(map[string]int)
+	require.NoError(t, chunkedStore.MGet(ctx, keys, &values))
+	assert.Equal(t, 4, mr.CommandCount()-count)
+	assert.Len(t, values, len(keys))
+	for i, key := range keys {
+		assert.Equal(t, i, values[key])
+	}
+
+	// 分批的pipeline
+	require.NoError(t, chunkedStore.MSet(ctx, items, time.Hour))
+	exists, err := chunkedStore.Exists(ctx, append(keys, "chunk:missing"))
+	require.NoError(t, err)
+	assert.Len(t, exists, len(keys)+1)
+	assert.False(t, exists["chunk:missing"])
+	assert.True(t, exists["chunk:9"])
+
+	count = mr.CommandCount()
+	deleted, err := chunkedStore.Del(ctx, keys...)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(keys)), deleted)
+	assert.Equal(t, 4, mr.CommandCount()-count)
+
+	// 有条件的写入同样分批
+	written, err := chunkedStore.MSetNX(ctx, items, time.Hour)
+	require.NoError(t, err)
+	assert.Len(t, written, len(keys))
+	for _, key := range keys {
+		assert.True(t, written[key])
+	}
+	written, err = chunkedStore.MSetXX(ctx, map[string]interface{}{"chunk:0": 1, "chunk:missing": 1}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"chunk:0": true, "chunk:missing": false}, written)
+
+	// 过期时间和计数器的pipeline同样分批
+	var pipelines pipelineCounter
+	client.AddHook(&pipelines)
+	ttls, err := chunkedStore.TTL(ctx, keys)
+	require.NoError(t, err)
+	assert.Len(t, ttls, len(keys))
+	assert.Equal(t, int64(4), pipelines.Swap(0))
+
+	updated, err := chunkedStore.Expire(ctx, keys, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(keys)), updated)
+	assert.Equal(t, int64(4), pipelines.Swap(0))
+
+	updated, err = chunkedStore.Persist(ctx, keys)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(keys)), updated)
+	assert.Equal(t, int64(4), pipelines.Swap(0))
+
+	deltas := make(map[string]int64, len(keys))
+	for i := range keys {
+		deltas[fmt.Sprintf("counter:%d", i)] = int64(i)
+	}
+	counters, err := chunkedStore.MIncrBy(ctx, deltas, 0)
+	require.NoError(t, err)
+	for key, delta := range deltas {
+		assert.Equal(t, delta, counters[key])
+	}
+	assert.Equal(t, int64(4), pipelines.Swap(0))
+
+	// 取消的ctx不再发送剩余批次
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, chunkedStore.MSet(canceled, items, 0), context.Canceled)
+	_, err = chunkedStore.MSetNX(canceled, items, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// pipelineCounter 统计客户端执行的pipeline数量
+type pipelineCounter struct {
+	atomic.Int64
+}
+
+func (c *pipelineCounter) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (c *pipelineCounter) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (c *pipelineCounter) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		c.Add(1)
+		return next(ctx, cmds)
+	}
+}
+
+// collectKeys 收集Scan返回的所有键并去重
+func collectKeys(t *testing.T, seq func(yield func(string, error) bool)) map[string]bool {
+	keys := make(map[string]bool)
+	for key, err := range seq {
+		require.NoError(t, err)
+		keys[key] = true
+	}
+	return keys
+}
+
+func TestRedisStoreScan(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	redisStore := NewStore(client)
+
+	items := make(map[string]interface{})
+	for i := 0; i < 30; i++ {
+		items[fmt.Sprintf("user:%d", i)] = i
+	}
+	items["order:1"] = 1
+	require.NoError(t, redisStore.MSet(ctx, items, 0))
+
+	keys := collectKeys(t, redisStore.Scan(ctx, "user:*", 0, 7))
+	assert.Len(t, keys, 30)
+	assert.False(t, keys["order:1"])
+
+	// 空模式匹配所有键
+	assert.Len(t, collectKeys(t, redisStore.Scan(ctx, "", 0, 0)), 31)
+
+	// 提前停止遍历
+	count := 0
+	for range redisStore.Scan(ctx, "*", 0, 5) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+
+	// 错误作为最后一个元素返回
+	mr.Close()
+	var lastErr error
+	for _, err := range redisStore.Scan(ctx, "*", 0, 0) {
+		lastErr = err
+	}
+	assert.Error(t, lastErr)
+}
+
+func TestRedisStoreScanRing(t *testing.T) {
+	ctx := context.Background()
+	addrs := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+		addrs[fmt.Sprintf("shard%d", i)] = mr.Addr()
+	}
+
+	ring := redis.NewRing(&redis.RingOptions{Addrs: addrs})
+	defer ring.Close()
+	ringStore := NewStore(ring)
+
+	items := make(map[string]interface{})
+	for i := 0; i < 30; i++ {
+		items[fmt.Sprintf("user:%d", i)] = i
+	}
+	require.NoError(t, ringStore.MSet(ctx, items, 0))
+
+	// 遍历所有分片
+	assert.Len(t, collectKeys(t, ringStore.Scan(ctx, "user:*", 0, 0)), 30)
+}
+
+func TestRedisStoreExpirer(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	redisStore := NewStore(client)
+
+	// 运行Expirer测试
+	store.NewTestHelper(t, redisStore).TestExpirer()
+
+	// 缩短TTL后键按新的时间过期
+	require.NoError(t, redisStore.MSet(ctx, map[string]interface{}{"k": "v"}, time.Hour))
+	_, err = redisStore.Expire(ctx, []string{"k"}, time.Second)
+	require.NoError(t, err)
+	mr.FastForward(2 * time.Second)
+
+	exists, err := redisStore.Exists(ctx, []string{"k"})
+	require.NoError(t, err)
+	assert.False(t, exists["k"])
+}
+
+func TestRedisStoreCounter(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	redisStore := NewStore(client)
+
+	// 运行Counter测试
+	store.NewTestHelper(t, redisStore).TestCounter()
+
+	// TTL在计数器创建时设置
+	_, err = redisStore.IncrBy(ctx, "hits", 1, time.Second)
+	require.NoError(t, err)
+	mr.FastForward(2 * time.Second)
+
+	value, err := redisStore.IncrBy(ctx, "hits", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+}
+
+func TestRedisStoreCounterRing(t *testing.T) {
+	ctx := context.Background()
+	addrs := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+		addrs[fmt.Sprintf("shard%d", i)] = mr.Addr()
+	}
+
+	ring := redis.NewRing(&redis.RingOptions{Addrs: addrs})
+	defer ring.Close()
+	ringStore := NewStore(ring)
+
+	// 脚本需要在每个分片上加载
+	deltas := make(map[string]int64)
+	for i := 0; i < 30; i++ {
+		deltas[fmt.Sprintf("counter:%d", i)] = int64(i)
+	}
+	values, err := ringStore.MIncrBy(ctx, deltas, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, deltas, values)
+
+	values, err = ringStore.MIncrBy(ctx, deltas, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(58), values["counter:29"])
+}
+
+func TestRedisStoreCAS(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	redisStore := NewStore(client)
+
+	// 运行CAS测试
+	store.NewTestHelper(t, redisStore).TestCAS()
+
+	// 脚本计算的版本与客户端一致，TTL随写入设置
+	var result string
+	_, err = redisStore.SetIfVersion(ctx, "k", "v", store.NoVersion, time.Second)
+	require.NoError(t, err)
+	version, _, err := redisStore.GetVersioned(ctx, "k", &result)
+	require.NoError(t, err)
+	set, err := redisStore.SetIfVersion(ctx, "k", "v2", version, time.Second)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	mr.FastForward(2 * time.Second)
+	_, found, err := redisStore.GetVersioned(ctx, "k", &result)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRedisStoreConditionalSetter(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	redisStore := NewStore(client)
+
+	// 运行ConditionalSetter测试
+	store.NewTestHelper(t, redisStore).TestConditionalSetter()
+
+	// 运行EntrySetter测试
+	store.NewTestHelper(t, redisStore).TestEntrySetter()
+
+	// 过期后可以再次以NX写入
+	written, err := redisStore.MSetNX(ctx, map[string]interface{}{"lock": "a"}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, written["lock"])
+	written, err = redisStore.MSetNX(ctx, map[string]interface{}{"lock": "b"}, time.Second)
+	require.NoError(t, err)
+	assert.False(t, written["lock"])
+
+	mr.FastForward(2 * time.Second)
+	written, err = redisStore.MSetNX(ctx, map[string]interface{}{"lock": "b"}, time.Second)
+	require.NoError(t, err)
+	assert.True(t, written["lock"])
+}
+
+func TestRedisStorePubSub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	redisStore := NewStore(client)
+
+	messages := make(chan string, 1)
+	require.NoError(t, redisStore.Subscribe(ctx, "events", func(message string) {
+		messages <- message
+	}))
+
+	// 订阅返回后发布的消息一定会被收到
+	require.NoError(t, redisStore.Publish(ctx, "events", "hello"))
+	select {
+	case message := <-messages:
+		assert.Equal(t, "hello", message)
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+}
+
+func TestRedisStoreOnEvictKeyspace(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), DB: 2})
+	defer client.Close()
+	redisStore := NewStoreWithOptions(client, &Options{EventSource: EventSourceKeyspace})
+	defer redisStore.events.stop()
+
+	events := make(chan store.Event, 8)
+	redisStore.OnEvict(func(event store.Event) {
+		events <- event
+	})
+	require.False(t, redisStore.events.emulated.Load())
+
+	// miniredis不产生键空间通知，手动发布服务端会发送的消息
+	require.NoError(t, client.Publish(ctx, "__keyevent@2__:expired", "a").Err())
+	require.NoError(t, client.Publish(ctx, "__keyevent@2__:evicted", "b").Err())
+	require.NoError(t, client.Publish(ctx, "__keyevent@2__:set", "ignored").Err())
+	require.NoError(t, client.Publish(ctx, "__keyevent@2__:del", "c").Err())
+
+	expected := []store.Event{
+		{Key: "a", Reason: store.EvictExpired},
+		{Key: "b", Reason: store.EvictCapacity},
+		{Key: "c", Reason: store.EvictDeleted},
+	}
+	for _, want := range expected {
+		select {
+		case event := <-events:
+			assert.Equal(t, want, event)
+		case <-time.After(time.Second):
+			t.Fatalf("event for %s not received", want.Key)
+		}
+	}
+
+	// 订阅键空间通知时Store自身的删除不再模拟事件，避免重复
+	require.NoError(t, redisStore.MSet(ctx, map[string]interface{}{"d": 1}, 0))
+	_, err = redisStore.Del(ctx, "d")
+	require.NoError(t, err)
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestKeyeventFlagsEnabled(t *testing.T) {
+	assert.True(t, keyeventFlagsEnabled("AE"))
+	assert.True(t, keyeventFlagsEnabled("Exeg"))
+	assert.True(t, keyeventFlagsEnabled("gxeKE"))
+	assert.False(t, keyeventFlagsEnabled(""))
+	assert.False(t, keyeventFlagsEnabled("KA"))
+	assert.False(t, keyeventFlagsEnabled("Ex"))
+}
+
+func TestRedisStoreWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	// miniredis不支持键空间通知，Watch退回PublishWrites频道
+	redisStore := NewStoreWithOptions(client, &Options{PublishWrites: true})
+	store.NewTestHelper(t, redisStore).TestWatcher()
+	assert.False(t, redisStore.watchKeyspace())
+
+	events, err := redisStore.Watch(ctx, "user:*")
+	require.NoError(t, err)
+
+	// 其他进程中启用了PublishWrites的Store的写入同样可以收到
+	writer := NewStoreWithOptions(client, &Options{PublishWrites: true})
+	_, err = writer.MSetNX(ctx, map[string]interface{}{"user:1": "a"}, 0)
+	require.NoError(t, err)
+	_, err = writer.IncrBy(ctx, "user:2", 1, 0)
+	require.NoError(t, err)
+
+	// 未启用PublishWrites的Store不发布
+	require.NoError(t, NewStore(client).MSet(ctx, map[string]interface{}{"user:3": "c"}, 0))
+
+	// 没有写入的条件写入不产生事件
+	_, err = writer.MSetNX(ctx, map[string]interface{}{"user:1": "b"}, 0)
+	require.NoError(t, err)
+	_, err = writer.Del(ctx, "user:1")
+	require.NoError(t, err)
+
+	for _, want := range []store.WatchEvent{
+		{Key: "user:1", Type: store.WatchSet},
+		{Key: "user:2", Type: store.WatchSet},
+		{Key: "user:1", Type: store.WatchDelete},
+	} {
+		select {
+		case event := <-events:
+			assert.Equal(t, want, event)
+		case <-time.After(time.Second):
+			t.Fatalf("%s event for %s not received", want.Type, want.Key)
+		}
+	}
+	assert.Zero(t, redisStore.WatchDropped())
+}
+
+func TestRedisStoreWatchKeyspace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), DB: 1})
+	defer client.Close()
+	redisStore := NewStoreWithOptions(client, &Options{EventSource: EventSourceKeyspace, PublishWrites: true})
+
+	events, err := redisStore.Watch(ctx, "user:*")
+	require.NoError(t, err)
+
+	// 使用键空间通知时不再发布写入
+	require.NoError(t, redisStore.MSet(ctx, map[string]interface{}{"user:9": "v"}, 0))
+
+	// miniredis不产生键空间通知，手动发布服务端会发送的消息
+	require.NoError(t, client.Publish(ctx, "__keyspace@1__:user:1", "set").Err())
+	require.NoError(t, client.Publish(ctx, "__keyspace@1__:user:1", "expire").Err())
+	require.NoError(t, client.Publish(ctx, "__keyspace@1__:order:1", "set").Err())
+	require.NoError(t, client.Publish(ctx, "__keyspace@0__:user:1", "set").Err())
+	require.NoError(t, client.Publish(ctx, "__keyspace@1__:user:1", "expired").Err())
+	require.NoError(t, client.Publish(ctx, "__keyspace@1__:user:2", "evicted").Err())
+
+	for _, want := range []store.WatchEvent{
+		{Key: "user:1", Type: store.WatchSet},
+		{Key: "user:1", Type: store.WatchExpire},
+		{Key: "user:2", Type: store.WatchDelete},
+	} {
+		select {
+		case event := <-events:
+			assert.Equal(t, want, event)
+		case <-time.After(time.Second):
+			t.Fatalf("%s event for %s not received", want.Type, want.Key)
+		}
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 取消后channel关闭
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("watch channel not closed")
+	}
+}
+
+func TestKeyspaceFlagsEnabled(t *testing.T) {
+	assert.True(t, keyspaceFlagsEnabled("KA", false))
+	assert.True(t, keyspaceFlagsEnabled("Kg$x", false))
+	assert.False(t, keyspaceFlagsEnabled("Kg$x", true))
+	assert.True(t, keyspaceFlagsEnabled("Kg$xh", true))
+	assert.False(t, keyspaceFlagsEnabled("EA", false))
+	assert.False(t, keyspaceFlagsEnabled("K$x", false))
+}
+
+func TestRedisStoreAtomicWrites(t *testing.T) {
+	ctx := context.Background()
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	atomicStore := NewStoreWithOptions(client, &Options{AtomicWrites: true})
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, atomicStore)
+	testHelper.RunAllTests()
+	testHelper.TestEntrySetter()
+
+	require.NoError(t, atomicStore.MSet(ctx, map[string]interface{}{"a": 1, "b": 2}, time.Hour))
+	assert.Equal(t, time.Hour, mr.TTL("a"))
+	assert.Equal(t, time.Hour, mr.TTL("b"))
+
+	// 脚本已加载时每次写入只有一条EVALSHA(miniredis同时计入脚本内的两次SET)
+	count := mr.CommandCount()
+	require.NoError(t, atomicStore.MSet(ctx, map[string]interface{}{"a": 3, "b": 4}, 0))
+	assert.Equal(t, 3, mr.CommandCount()-count)
+	assert.Equal(t, time.Duration(0), mr.TTL("a"))
+
+	// 脚本缓存被清空后自动重新加载
+	require.NoError(t, client.ScriptFlush(ctx).Err())
+	require.NoError(t, atomicStore.MSet(ctx, map[string]interface{}{"a": 5}, 0))
+	var value int
+	_, err = atomicStore.Get(ctx, "a", &value)
+	require.NoError(t, err)
+	assert.Equal(t, 5, value)
+
+	// 任一值无法序列化时不写入任何键
+	err = atomicStore.MSet(ctx, map[string]interface{}{"ok1": 1, "ok2": 2, "bad": make(chan int)}, time.Hour)
+	assert.Error(t, err)
+	assert.False(t, mr.Exists("ok1"))
+	assert.False(t, mr.Exists("ok2"))
+
+	// 脚本先校验所有参数，参数有误时不写入任何键
+	err = msetScript.Run(ctx, client, []string{"x", "y"}, `"x"`, 1000, `"y"`, "invalid").Err()
+	assert.ErrorContains(t, err, "invalid ttl for key y")
+	assert.False(t, mr.Exists("x"))
+	assert.False(t, mr.Exists("y"))
+
+	// 非原子的pipeline写入在同样的情况下会留下部分键
+	pipe := client.Pipeline()
+	pipe.Set(ctx, "x", `"x"`, time.Second)
+	pipe.Do(ctx, "SET", "y", `"y"`, "PX", "invalid")
+	_, err = pipe.Exec(ctx)
+	assert.Error(t, err)
+	assert.True(t, mr.Exists("x"))
+}
+
+func TestRedisStoreAtomicWritesRing(t *testing.T) {
+	ctx := context.Background()
+
+	addrs := make(map[string]string)
+	for i := 0; i < 3; i++ {
+		mr, err := miniredis.Run()
+		require.NoError(t, err)
+		defer mr.Close()
+		addrs[fmt.Sprintf("shard%d", i)] = mr.Addr()
+	}
+
+	ring := redis.NewRing(&redis.RingOptions{Addrs: addrs})
+	defer ring.Close()
+	atomicStore := NewStoreWithOptions(ring, &Options{AtomicWrites: true})
+
+	// 脚本需要在每个分片上加载
+	testHelper := store.NewTestHelper(t, atomicStore)
+	testHelper.RunAllTests()
+	testHelper.TestEntrySetter()
+
+	keys := make([]string, 20)
+	items := make(map[string]interface{})
+	for i := range keys {
+		keys[i] = fmt.Sprintf("{user%d}:name", i)
+		items[keys[i]] = i
+	}
+	require.NoError(t, atomicStore.MSet(ctx, items, time.Hour))
+
+	values := make(map[string]int)
+	require.NoError(t, atomicStore.MGet(ctx, keys, &values))
+	assert.Len(t, values, len(items))
 }
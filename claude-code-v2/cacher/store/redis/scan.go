@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// defaultScanCount 未指定count时每次SCAN的COUNT参数
+const defaultScanCount = 100
+
+// Scan 使用SCAN MATCH遍历匹配pattern的键
+// 与Redis一致，遍历期间被修改的键可能重复出现或被遗漏，调用方需要自行去重。
+// cursor仅对单节点客户端有效；Cluster和Ring会依次遍历每个主节点或分片，忽略cursor。
+func (s *Store) Scan(ctx context.Context, pattern string, cursor uint64, count int64) iter.Seq2[string, error] {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if count <= 0 {
+		count = defaultScanCount
+	}
+
+	return func(yield func(string, error) bool) {
+		if s.topology == topologySingle {
+			scanNode(ctx, s.client, pattern, cursor, count, yield)
+			return
+		}
+
+		nodes, err := s.masterNodes(ctx)
+		if err != nil {
+			yield("", fmt.Errorf("redis scan error: %w", err))
+			return
+		}
+		for _, node := range nodes {
+			if !scanNode(ctx, node, pattern, 0, count, yield) {
+				return
+			}
+		}
+	}
+}
+
+// scanNode 在单个节点上执行SCAN直到游标归零
+// 返回: 调用方是否需要继续遍历
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string, cursor uint64, count int64, yield func(string, error) bool) bool {
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, count).Result()
+		if err != nil {
+			yield("", fmt.Errorf("redis scan error: %w", err))
+			return false
+		}
+
+		for _, key := range keys {
+			if !yield(key, nil) {
+				return false
+			}
+		}
+
+		if next == 0 {
+			return true
+		}
+		cursor = next
+	}
+}
+
+// masterNodes 返回Cluster的所有主节点或Ring的所有分片
+func (s *Store) masterNodes(ctx context.Context) ([]*redis.Client, error) {
+	var mutex sync.Mutex
+	nodes := make([]*redis.Client, 0)
+	collect := func(ctx context.Context, client *redis.Client) error {
+		mutex.Lock()
+		nodes = append(nodes, client)
+		mutex.Unlock()
+		return nil
+	}
+
+	var err error
+	switch client := s.client.(type) {
+	case *redis.ClusterClient:
+		err = client.ForEachMaster(ctx, collect)
+	case *redis.Ring:
+		err = client.ForEachShard(ctx, collect)
+	}
+	return nodes, err
+}
+
+// 确保Store实现了store.Scanner接口
+var _ store.Scanner = (*Store)(nil)
@@ -0,0 +1,245 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"go-cache/cacher/store"
+)
+
+// defaultWatchChannel PublishWrites默认使用的频道
+const defaultWatchChannel = "go-cache:watch"
+
+// keyspaceWatchTypes 键空间通知的事件名到Watch事件类型的映射，
+// 未列出的事件(如只修改过期时间的expire、persist)被忽略
+var keyspaceWatchTypes = map[string]store.WatchEventType{
+	"set":          store.WatchSet,
+	"append":       store.WatchSet,
+	"setrange":     store.WatchSet,
+	"incrby":       store.WatchSet,
+	"incrbyfloat":  store.WatchSet,
+	"hset":         store.WatchSet,
+	"hdel":         store.WatchSet,
+	"hincrby":      store.WatchSet,
+	"hincrbyfloat": store.WatchSet,
+	"restore":      store.WatchSet,
+	"rename_to":    store.WatchSet,
+	"copy_to":      store.WatchSet,
+	"del":          store.WatchDelete,
+	"rename_from":  store.WatchDelete,
+	"evicted":      store.WatchDelete,
+	"expired":      store.WatchExpire,
+}
+
+// publishedWatchTypes PublishWrites消息中的事件名到Watch事件类型的映射
+var publishedWatchTypes = map[string]store.WatchEventType{
+	store.WatchSet.String():    store.WatchSet,
+	store.WatchDelete.String(): store.WatchDelete,
+	store.WatchExpire.String(): store.WatchExpire,
+}
+
+// watchState Watch的状态，在首次使用时确定是否使用键空间通知
+type watchState struct {
+	once     sync.Once
+	keyspace bool
+
+	// hub 只用于为各订阅者分配有界缓冲区和统计丢弃的事件，事件由各自的订阅发送
+	hub store.WatchHub
+}
+
+// Watch 订阅匹配pattern的键的变化，每次调用建立独立的订阅
+// 键空间通知可用时使用PSUBSCRIBE __keyspace@<db>__:<pattern>，报告任意客户端的写入、删除、淘汰(WatchDelete)和过期；
+// 否则订阅PublishWrites频道并在本地按pattern过滤，只能收到启用了PublishWrites的Store的写入和删除。
+// 订阅者处理过慢时丢弃事件，丢弃数量见WatchEvent.Missed和WatchDropped
+func (s *Store) Watch(ctx context.Context, pattern string) (<-chan store.WatchEvent, error) {
+	if s.watchKeyspace() {
+		return s.watchKeyspaceEvents(ctx, pattern)
+	}
+	return s.watchPublished(ctx, pattern)
+}
+
+// WatchDropped 返回所有Watch订阅者因处理过慢累计丢弃的事件数量
+func (s *Store) WatchDropped() uint64 {
+	return s.watch.hub.Dropped()
+}
+
+// watchKeyspace 判断Watch是否使用键空间通知，结果在首次调用时确定
+func (s *Store) watchKeyspace() bool {
+	s.watch.once.Do(func() {
+		switch s.eventSource {
+		case EventSourceKeyspace:
+			s.watch.keyspace = true
+		case EventSourceAuto:
+			ctx, cancel := context.WithTimeout(context.Background(), eventsSetupTimeout)
+			defer cancel()
+			s.watch.keyspace = s.keyspaceWatchEnabled(ctx)
+		}
+	})
+	return s.watch.keyspace
+}
+
+// keyspaceWatchEnabled 检查每个节点是否都启用了Watch所需的键空间通知
+func (s *Store) keyspaceWatchEnabled(ctx context.Context) bool {
+	nodes, err := s.eventNodes(ctx)
+	if err != nil || len(nodes) == 0 {
+		return false
+	}
+
+	for _, node := range nodes {
+		config, err := node.ConfigGet(ctx, "notify-keyspace-events").Result()
+		if err != nil || !keyspaceFlagsEnabled(config["notify-keyspace-events"], s.hashLayout) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyspaceFlagsEnabled 判断notify-keyspace-events的取值是否包含键空间事件(K)以及通用命令(g)、字符串($)和过期(x)，
+// 哈希布局下还需要哈希命令(h)
+func keyspaceFlagsEnabled(flags string, hashLayout bool) bool {
+	if !strings.Contains(flags, "K") {
+		return false
+	}
+	if strings.Contains(flags, "A") {
+		return true
+	}
+	required := "g$x"
+	if hashLayout {
+		required += "h"
+	}
+	for _, flag := range required {
+		if !strings.ContainsRune(flags, flag) {
+			return false
+		}
+	}
+	return true
+}
+
+// watchKeyspaceEvents 在每个节点上以模式订阅键空间频道
+func (s *Store) watchKeyspaceEvents(ctx context.Context, pattern string) (<-chan store.WatchEvent, error) {
+	nodes, err := s.eventNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("redis keyspace events error: %w", err)
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	type subscription struct {
+		pubsub *redis.PubSub
+		prefix string
+	}
+	subscriptions := make([]subscription, 0, len(nodes))
+	closeAll := func() {
+		for _, sub := range subscriptions {
+			_ = sub.pubsub.Close()
+		}
+	}
+
+	for _, node := range nodes {
+		client, ok := node.(patternSubscriber)
+		if !ok {
+			closeAll()
+			return nil, fmt.Errorf("redis client %T does not support subscribe", node)
+		}
+
+		db := 0
+		if c, ok := node.(*redis.Client); ok {
+			db = c.Options().DB
+		}
+		prefix := fmt.Sprintf("__keyspace@%d__:", db)
+		pubsub := client.PSubscribe(ctx, prefix+pattern)
+		subscriptions = append(subscriptions, subscription{pubsub: pubsub, prefix: prefix})
+		if _, err := pubsub.Receive(ctx); err != nil {
+			closeAll()
+			return nil, fmt.Errorf("redis psubscribe error: %w", err)
+		}
+	}
+
+	stream := s.watch.hub.Add(ctx, pattern)
+	for _, sub := range subscriptions {
+		s.forwardWatch(ctx, sub.pubsub, stream, func(msg *redis.Message) (store.WatchEvent, bool) {
+			key, ok := strings.CutPrefix(msg.Channel, sub.prefix)
+			if !ok {
+				return store.WatchEvent{}, false
+			}
+			eventType, ok := keyspaceWatchTypes[msg.Payload]
+			return store.WatchEvent{Key: key, Type: eventType}, ok
+		})
+	}
+	return stream.Events(), nil
+}
+
+// watchPublished 订阅PublishWrites频道，在本地按pattern过滤
+func (s *Store) watchPublished(ctx context.Context, pattern string) (<-chan store.WatchEvent, error) {
+	client, ok := s.client.(subscriber)
+	if !ok {
+		return nil, fmt.Errorf("redis client %T does not support subscribe", s.client)
+	}
+
+	pubsub := client.Subscribe(ctx, s.watchChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("redis subscribe error: %w", err)
+	}
+
+	stream := s.watch.hub.Add(ctx, pattern)
+	s.forwardWatch(ctx, pubsub, stream, func(msg *redis.Message) (store.WatchEvent, bool) {
+		name, key, ok := strings.Cut(msg.Payload, ":")
+		if !ok || !store.MatchPattern(pattern, key) {
+			return store.WatchEvent{}, false
+		}
+		eventType, ok := publishedWatchTypes[name]
+		return store.WatchEvent{Key: key, Type: eventType}, ok
+	})
+	return stream.Events(), nil
+}
+
+// forwardWatch 在后台将订阅收到的消息转换为事件发送给订阅者，ctx被取消时关闭订阅
+func (s *Store) forwardWatch(ctx context.Context, pubsub *redis.PubSub, stream *store.WatchStream,
+	convert func(msg *redis.Message) (store.WatchEvent, bool)) {
+	context.AfterFunc(ctx, func() { _ = pubsub.Close() })
+
+	messages := pubsub.Channel()
+	go func() {
+		for msg := range messages {
+			if event, ok := convert(msg); ok {
+				stream.Send(event)
+			}
+		}
+	}()
+}
+
+// publishingWrites 判断写入后是否需要向PublishWrites频道发布消息
+func (s *Store) publishingWrites() bool {
+	return s.publishWrites && !s.watchKeyspace()
+}
+
+// notifyWrites 启用PublishWrites且键空间通知不可用时，为每个键发布一条"类型:键"消息
+func (s *Store) notifyWrites(ctx context.Context, eventType store.WatchEventType, keys []string) {
+	if len(keys) == 0 || !s.publishingWrites() {
+		return
+	}
+
+	pipe := s.client.Pipeline()
+	for _, key := range keys {
+		pipe.Publish(ctx, s.watchChannel, eventType.String()+":"+key)
+	}
+	// 通知不保证送达，发布失败不影响写入结果
+	_, _ = pipe.Exec(ctx)
+}
+
+// itemKeys 返回map的所有键
+func itemKeys[V any](items map[string]V) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// 确保Store实现了store.Watcher接口
+var _ store.Watcher = (*Store)(nil)
@@ -0,0 +1,140 @@
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// maxRequestBody 单个请求体的最大字节数
+const maxRequestBody = 64 << 20
+
+// Handler 通过HTTP对外提供store.Store的处理器，与本包的Store客户端配合使用
+// 值以原始字节透传，因此后端Store应直接保存[]byte(如Ristretto)或使用store.RawCodec。
+// 可以通过http.StripPrefix挂载到任意路径下。
+type Handler struct {
+	store store.Store
+	mux   *http.ServeMux
+}
+
+// NewHandler 创建服务于s的HTTP处理器
+func NewHandler(s store.Store) *Handler {
+	h := &Handler{
+		store: s,
+		mux:   http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("POST "+pathGet, h.handleGet)
+	h.mux.HandleFunc("POST "+pathSet, h.handleSet)
+	h.mux.HandleFunc("POST "+pathDelete, h.handleDelete)
+	h.mux.HandleFunc("POST "+pathExists, h.handleExists)
+
+	return h
+}
+
+// ServeHTTP 实现http.Handler接口
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) handleGet(w http.ResponseWriter, r *http.Request) {
+	var req keysRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	// 无法解码的键按未命中返回
+	values := make(map[string][]byte, len(req.Keys))
+	var batchErr *store.BatchError
+	if err := h.store.MGet(r.Context(), req.Keys, &values); err != nil && !errors.As(err, &batchErr) {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, getResponse{Values: values})
+}
+
+func (h *Handler) handleSet(w http.ResponseWriter, r *http.Request) {
+	var req setRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.TTL < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid ttl %d", req.TTL))
+		return
+	}
+
+	items := make(map[string]interface{}, len(req.Items))
+	for key, value := range req.Items {
+		items[key] = value
+	}
+
+	if err := h.store.MSet(r.Context(), items, time.Duration(req.TTL)*time.Millisecond); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, struct{}{})
+}
+
+func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) {
+	var req keysRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	deleted, err := h.store.Del(r.Context(), req.Keys...)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, deleteResponse{Deleted: deleted})
+}
+
+func (h *Handler) handleExists(w http.ResponseWriter, r *http.Request) {
+	var req keysRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	exists, err := h.store.Exists(r.Context(), req.Keys)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, existsResponse{Exists: exists})
+}
+
+// decodeRequest 解析请求体，失败时写入400响应并返回false
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		status := http.StatusBadRequest
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeJSON 写入200响应
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 写入错误响应
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,42 @@
+package remote
+
+// HTTP接口路径，所有请求均为POST，请求和响应体为JSON
+// 值以[]byte传输(JSON中为base64)，服务端不做任何解码，原样交给后端Store保存
+const (
+	pathGet    = "/get"
+	pathSet    = "/set"
+	pathDelete = "/delete"
+	pathExists = "/exists"
+)
+
+// keysRequest GET/DELETE/EXISTS的请求体
+type keysRequest struct {
+	Keys []string `json:"keys"`
+}
+
+// getResponse GET的响应体，只包含存在的键
+type getResponse struct {
+	Values map[string][]byte `json:"values"`
+}
+
+// setRequest SET的请求体
+type setRequest struct {
+	Items map[string][]byte `json:"items"`
+	// TTL 过期时间(毫秒)，0表示永不过期
+	TTL int64 `json:"ttl_ms,omitempty"`
+}
+
+// deleteResponse DELETE的响应体
+type deleteResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// existsResponse EXISTS的响应体
+type existsResponse struct {
+	Exists map[string]bool `json:"exists"`
+}
+
+// errorResponse 请求失败时的响应体
+type errorResponse struct {
+	Error string `json:"error"`
+}
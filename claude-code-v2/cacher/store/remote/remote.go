@@ -0,0 +1,476 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// Options 远程Store配置
+type Options struct {
+	// Codec 值编解码器，为nil时使用store.DefaultCodec
+	// 客户端完成编码，服务端只透传字节，因此共享同一缓存的客户端应使用相同的Codec
+	Codec store.Codec
+
+	// HTTPClient 发送请求使用的客户端，为nil时使用带连接池的默认客户端
+	HTTPClient *http.Client
+
+	// Timeout 默认客户端单次请求的超时时间，同时限制合并的Get请求(含重试)的总时间，
+	// 0使用默认值10秒，负数表示不限制
+	Timeout time.Duration
+
+	// BatchSize 单个请求最多包含的键数量，超出时拆分为多个请求，0使用默认值1000
+	BatchSize int
+
+	// BatchWindow 合并并发Get的等待时间，窗口内的Get会合并为一个请求，0表示不合并
+	BatchWindow time.Duration
+
+	// MaxRetries 网络错误、服务端5xx或429时的最大重试次数，0使用默认值2，负数表示不重试
+	MaxRetries int
+
+	// RetryBackoff 首次重试前的等待时间，之后每次翻倍，0使用默认值50ms
+	RetryBackoff time.Duration
+
+	// DeleteCorrupt 启用后MGet中无法解码的键在返回*store.BatchError的同时从服务端删除
+	DeleteCorrupt bool
+}
+
+// Error 服务端返回的错误
+type Error struct {
+	// StatusCode HTTP状态码
+	StatusCode int
+	// Message 服务端的错误信息
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("remote store error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Store 通过HTTP访问远程Handler的Store实现
+type Store struct {
+	baseURL string
+	client  *http.Client
+	opts    Options
+	codec   store.Codec
+
+	// ownsTransport 默认客户端由Store创建，Close时需要释放其连接
+	ownsTransport bool
+
+	batchMutex sync.Mutex
+	pending    []*getCall
+	timer      *time.Timer
+}
+
+// getCall 等待合并发送的Get调用
+type getCall struct {
+	ctx   context.Context
+	key   string
+	value []byte
+	found bool
+	err   error
+	done  chan struct{}
+}
+
+// NewStore 创建访问baseURL处Handler的远程Store实例
+// baseURL: Handler的挂载地址，如http://cache.internal:8080/cache
+// opts: 配置项，可以为nil使用默认配置
+func NewStore(baseURL string, opts *Options) *Store {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 2
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 50 * time.Millisecond
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 10 * time.Second
+	}
+
+	s := &Store{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  o.HTTPClient,
+		opts:    o,
+		codec:   o.Codec,
+	}
+
+	if s.client == nil {
+		// 默认Transport每个主机只保留2个空闲连接，并发访问同一服务端时需要更大的连接池
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = 100
+		transport.MaxIdleConnsPerHost = 100
+		s.client = &http.Client{Transport: transport, Timeout: max(o.Timeout, 0)}
+		s.ownsTransport = true
+	}
+
+	return s
+}
+
+// Close 释放默认客户端持有的空闲连接
+func (s *Store) Close() error {
+	if s.ownsTransport {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Get 从远程获取单个值
+func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	var value []byte
+	var found bool
+	var err error
+	if s.opts.BatchWindow > 0 {
+		value, found, err = s.batchGet(ctx, key)
+	} else {
+		var values map[string][]byte
+		values, err = s.get(ctx, []string{key})
+		value, found = values[key]
+	}
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := s.codec.Unmarshal(value, dst); err != nil {
+		return false, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return true, nil
+}
+
+// MGet 批量获取值到map中
+func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 验证dstMap是map指针
+	dstMapValue := reflect.ValueOf(dstMap)
+	if dstMapValue.Kind() != reflect.Ptr || dstMapValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("dstMap must be a pointer to map")
+	}
+
+	mapValue := dstMapValue.Elem()
+	mapType := mapValue.Type()
+	valueType := mapType.Elem()
+
+	// 如果map为nil，初始化它
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapType))
+	}
+
+	values, err := s.get(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	// 无法解码的键跳过并记录，不影响其他键
+	batchErr := &store.BatchError{}
+	for key, value := range values {
+		// 创建值类型的新实例并解码
+		valuePtr := reflect.New(valueType)
+		if err := s.codec.Unmarshal(value, valuePtr.Interface()); err != nil {
+			batchErr.Add(key, fmt.Errorf("failed to decode value: %w", err))
+			continue
+		}
+
+		mapValue.SetMapIndex(reflect.ValueOf(key), valuePtr.Elem())
+	}
+
+	if s.opts.DeleteCorrupt && len(batchErr.Errors) > 0 {
+		// 删除失败不影响返回结果，下次读取时会再次尝试
+		_, _ = s.Del(ctx, batchErr.Keys()...)
+	}
+	return batchErr.Err()
+}
+
+// Exists 批量检查键存在性
+func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	for _, chunk := range chunkKeys(keys, s.opts.BatchSize) {
+		var resp existsResponse
+		if err := s.do(ctx, pathExists, keysRequest{Keys: chunk}, &resp); err != nil {
+			return nil, err
+		}
+		for _, key := range chunk {
+			result[key] = resp.Exists[key]
+		}
+	}
+
+	return result, nil
+}
+
+// MSet 批量设置键值对，支持TTL
+func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	// 服务端TTL精度为毫秒，不足1毫秒按1毫秒处理
+	var ttlMillis int64
+	if ttl > 0 {
+		ttlMillis = int64((ttl + time.Millisecond - 1) / time.Millisecond)
+	}
+
+	req := setRequest{Items: make(map[string][]byte, min(len(items), s.opts.BatchSize)), TTL: ttlMillis}
+	for key, value := range items {
+		data, err := s.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value for key %s: %w", key, err)
+		}
+		req.Items[key] = data
+
+		if len(req.Items) >= s.opts.BatchSize {
+			if err := s.do(ctx, pathSet, req, nil); err != nil {
+				return err
+			}
+			req.Items = make(map[string][]byte, s.opts.BatchSize)
+		}
+	}
+
+	if len(req.Items) > 0 {
+		return s.do(ctx, pathSet, req, nil)
+	}
+	return nil
+}
+
+// Del 删除指定键
+func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var deletedCount int64
+	for _, chunk := range chunkKeys(keys, s.opts.BatchSize) {
+		var resp deleteResponse
+		if err := s.do(ctx, pathDelete, keysRequest{Keys: chunk}, &resp); err != nil {
+			return deletedCount, err
+		}
+		deletedCount += resp.Deleted
+	}
+
+	return deletedCount, nil
+}
+
+// get 按BatchSize分批获取原始字节，结果只包含存在的键
+func (s *Store) get(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	for _, chunk := range chunkKeys(keys, s.opts.BatchSize) {
+		var resp getResponse
+		if err := s.do(ctx, pathGet, keysRequest{Keys: chunk}, &resp); err != nil {
+			return nil, err
+		}
+		for key, value := range resp.Values {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// batchGet 将Get加入待发送队列，与BatchWindow内的其他Get合并为一个请求
+func (s *Store) batchGet(ctx context.Context, key string) ([]byte, bool, error) {
+	call := &getCall{ctx: ctx, key: key, done: make(chan struct{})}
+
+	s.batchMutex.Lock()
+	s.pending = append(s.pending, call)
+	if len(s.pending) >= s.opts.BatchSize {
+		// 队列已满，立即发送
+		if s.timer != nil {
+			s.timer.Stop()
+			s.timer = nil
+		}
+		calls := s.pending
+		s.pending = nil
+		s.batchMutex.Unlock()
+		go s.flush(calls)
+	} else {
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.opts.BatchWindow, s.flushPending)
+		}
+		s.batchMutex.Unlock()
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.found, call.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// flushPending 由定时器触发，发送当前队列中的所有Get
+func (s *Store) flushPending() {
+	s.batchMutex.Lock()
+	calls := s.pending
+	s.pending = nil
+	s.timer = nil
+	s.batchMutex.Unlock()
+
+	s.flush(calls)
+}
+
+// flush 以一个请求获取calls中的所有键并唤醒等待者
+// 请求由多个调用方共享，不使用任何一方的ctx，而是以Timeout限制总时间，避免服务端无响应时goroutine泄漏；
+// 发送前ctx已取消的调用直接结束，不再请求其键
+func (s *Store) flush(calls []*getCall) {
+	active := calls[:0]
+	for _, call := range calls {
+		if err := call.ctx.Err(); err != nil {
+			call.err = err
+			close(call.done)
+			continue
+		}
+		active = append(active, call)
+	}
+	calls = active
+	if len(calls) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(calls))
+	seen := make(map[string]bool, len(calls))
+	for _, call := range calls {
+		if !seen[call.key] {
+			seen[call.key] = true
+			keys = append(keys, call.key)
+		}
+	}
+
+	ctx := context.Background()
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+
+	values, err := s.get(ctx, keys)
+	for _, call := range calls {
+		call.value, call.found = values[call.key]
+		call.err = err
+		close(call.done)
+	}
+}
+
+// do 发送请求并解析响应，网络错误、5xx和429响应按指数退避重试
+// Get、Exists和Set重复执行结果不变；Delete重试时，已被前一次请求删除的键不再计入返回的数量
+func (s *Store) do(ctx context.Context, path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	backoff := s.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err = s.send(ctx, path, body, resp)
+		if err == nil || attempt >= s.opts.MaxRetries || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// send 发送一次请求
+func (s *Store) send(ctx context.Context, path string, body []byte, resp interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := s.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote request error: %w", &transportError{err: err})
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		data, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		if json.Unmarshal(data, &errResp) != nil || errResp.Error == "" {
+			errResp.Error = strings.TrimSpace(string(data))
+		}
+		return &Error{StatusCode: httpResp.StatusCode, Message: errResp.Error}
+	}
+
+	if resp == nil {
+		// 读完响应体以便连接复用
+		_, err = io.Copy(io.Discard, httpResp.Body)
+		return err
+	}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// transportError 请求未能得到响应的网络错误
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryable 判断错误是否可以重试：网络错误、服务端5xx或429
+// 编解码错误重试也不会成功，不重试
+func retryable(err error) bool {
+	var remoteErr *Error
+	if errors.As(err, &remoteErr) {
+		return remoteErr.StatusCode >= http.StatusInternalServerError || remoteErr.StatusCode == http.StatusTooManyRequests
+	}
+	var transportErr *transportError
+	if !errors.As(err, &transportErr) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// chunkKeys 按size将keys拆分为多个批次
+func chunkKeys(keys []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(keys)+size-1)/size)
+	for start := 0; start < len(keys); start += size {
+		end := min(start+size, len(keys))
+		chunks = append(chunks, keys[start:end])
+	}
+	return chunks
+}
+
+// 确保Store实现了store.Store和store.Closer接口
+var (
+	_ store.Store  = (*Store)(nil)
+	_ store.Closer = (*Store)(nil)
+)
@@ -0,0 +1,276 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+	"go-cache/cacher/store/ristretto"
+)
+
+// newTestServer 创建以Ristretto为后端的测试服务器，wrap可以包装处理器以注入故障或统计请求
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) (*httptest.Server, *ristretto.Store) {
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	t.Cleanup(func() { ristrettoStore.Close() })
+
+	var handler http.Handler = NewHandler(ristrettoStore)
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server, ristrettoStore
+}
+
+// countRequests 统计每个路径收到的请求数
+func countRequests(counts *sync.Map) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counter, _ := counts.LoadOrStore(r.URL.Path, new(atomic.Int64))
+			counter.(*atomic.Int64).Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRemoteStore(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+
+	remoteStore := NewStore(server.URL, nil)
+	defer remoteStore.Close()
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, remoteStore)
+	testHelper.RunAllTests()
+}
+
+func TestRemoteStoreBatchWindow(t *testing.T) {
+	server, _ := newTestServer(t, nil)
+
+	remoteStore := NewStore(server.URL, &Options{BatchWindow: 10 * time.Millisecond})
+	defer remoteStore.Close()
+
+	// 合并模式下同样需要通过通用测试套件
+	testHelper := store.NewTestHelper(t, remoteStore)
+	testHelper.RunAllTests()
+}
+
+func TestRemoteStoreRawPassthrough(t *testing.T) {
+	ctx := context.Background()
+	server, backend := newTestServer(t, nil)
+
+	remoteStore := NewStore(server.URL, &Options{Codec: store.RawCodec{}})
+	defer remoteStore.Close()
+
+	payload := []byte{0x00, 0xff, '\r', '\n', 'x'}
+	require.NoError(t, remoteStore.MSet(ctx, map[string]interface{}{"raw": payload}, 0))
+
+	// 后端保存的是客户端编码后的原始字节
+	var stored []byte
+	found, err := backend.Get(ctx, "raw", &stored)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, payload, stored)
+
+	var result []byte
+	found, err = remoteStore.Get(ctx, "raw", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, payload, result)
+}
+
+func TestRemoteStoreChunking(t *testing.T) {
+	ctx := context.Background()
+	var counts sync.Map
+	server, _ := newTestServer(t, countRequests(&counts))
+
+	remoteStore := NewStore(server.URL, &Options{BatchSize: 2})
+	defer remoteStore.Close()
+
+	items := make(map[string]interface{})
+	keys := make([]string, 0, 5)
+	for i := 0; i < 5; i++ {
+		key := fmt.Sprintf("key:%d", i)
+		items[key] = i
+		keys = append(keys, key)
+	}
+	require.NoError(t, remoteStore.MSet(ctx, items, time.Minute))
+
+	result := make(map[string]int)
+	require.NoError(t, remoteStore.MGet(ctx, keys, &result))
+	assert.Len(t, result, 5)
+
+	deleted, err := remoteStore.Del(ctx, keys...)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+
+	// 5个键按每批2个拆分为3个请求
+	for _, path := range []string{pathSet, pathGet, pathDelete} {
+		counter, ok := counts.Load(path)
+		require.True(t, ok, path)
+		assert.Equal(t, int64(3), counter.(*atomic.Int64).Load(), path)
+	}
+}
+
+func TestRemoteStoreCoalescesGets(t *testing.T) {
+	ctx := context.Background()
+	var counts sync.Map
+	server, _ := newTestServer(t, countRequests(&counts))
+
+	remoteStore := NewStore(server.URL, &Options{BatchWindow: 50 * time.Millisecond})
+	defer remoteStore.Close()
+
+	require.NoError(t, remoteStore.MSet(ctx, map[string]interface{}{"a": "1", "b": "2"}, 0))
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := []string{"a", "b", "missing"}[i%3]
+			found, err := remoteStore.Get(ctx, key, &results[i])
+			assert.NoError(t, err)
+			assert.Equal(t, key != "missing", found)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, "1", results[0])
+	assert.Equal(t, "2", results[1])
+
+	// 并发的Get合并为一个请求
+	counter, ok := counts.Load(pathGet)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), counter.(*atomic.Int64).Load())
+}
+
+func TestRemoteStoreBatchTimeout(t *testing.T) {
+	var counts sync.Map
+	release := make(chan struct{})
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return countRequests(&counts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 模拟无响应的服务端
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+	})
+	defer close(release)
+
+	remoteStore := NewStore(server.URL, &Options{BatchWindow: time.Millisecond, Timeout: 50 * time.Millisecond, MaxRetries: -1})
+	defer remoteStore.Close()
+
+	// 调用方不设置超时时，合并的请求也会在Timeout后结束
+	var result string
+	start := time.Now()
+	_, err := remoteStore.Get(context.Background(), "k", &result)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 发送前已取消的调用不再发送请求
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = remoteStore.Get(canceled, "k", &result)
+	assert.ErrorIs(t, err, context.Canceled)
+	time.Sleep(20 * time.Millisecond)
+	counter, ok := counts.Load(pathGet)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), counter.(*atomic.Int64).Load())
+}
+
+func TestRemoteStoreRetry(t *testing.T) {
+	ctx := context.Background()
+	var failures atomic.Int64
+	failures.Store(2)
+
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failures.Add(-1) >= 0 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	remoteStore := NewStore(server.URL, &Options{RetryBackoff: time.Millisecond})
+	defer remoteStore.Close()
+
+	// 前两次失败后第三次成功
+	require.NoError(t, remoteStore.MSet(ctx, map[string]interface{}{"k": "v"}, 0))
+
+	// 超过重试次数时返回服务端错误
+	failures.Store(3)
+	_, err := remoteStore.Del(ctx, "k")
+	var remoteErr *Error
+	require.True(t, errors.As(err, &remoteErr))
+	assert.Equal(t, http.StatusServiceUnavailable, remoteErr.StatusCode)
+}
+
+func TestRemoteStoreRetryableErrors(t *testing.T) {
+	ctx := context.Background()
+	var counts sync.Map
+	var status atomic.Int64
+	server, _ := newTestServer(t, func(next http.Handler) http.Handler {
+		return countRequests(&counts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if code := int(status.Load()); code != 0 {
+				http.Error(w, "rejected", code)
+				return
+			}
+			// 无法解析的响应
+			w.Write([]byte("not json"))
+		}))
+	})
+
+	remoteStore := NewStore(server.URL, &Options{RetryBackoff: time.Millisecond})
+	defer remoteStore.Close()
+	requests := func() int64 {
+		counter, ok := counts.LoadAndDelete(pathExists)
+		require.True(t, ok)
+		return counter.(*atomic.Int64).Load()
+	}
+
+	// 响应解析失败不重试
+	_, err := remoteStore.Exists(ctx, []string{"k"})
+	assert.ErrorContains(t, err, "failed to decode response")
+	assert.Equal(t, int64(1), requests())
+
+	// 429按重试次数重试
+	status.Store(http.StatusTooManyRequests)
+	_, err = remoteStore.Exists(ctx, []string{"k"})
+	var remoteErr *Error
+	require.True(t, errors.As(err, &remoteErr))
+	assert.Equal(t, http.StatusTooManyRequests, remoteErr.StatusCode)
+	assert.Equal(t, int64(3), requests())
+}
+
+func TestRemoteStoreClientErrorNotRetried(t *testing.T) {
+	ctx := context.Background()
+	var counts sync.Map
+	server, _ := newTestServer(t, countRequests(&counts))
+
+	remoteStore := NewStore(server.URL+"/missing", &Options{RetryBackoff: time.Millisecond})
+	defer remoteStore.Close()
+
+	_, err := remoteStore.Exists(ctx, []string{"k"})
+	var remoteErr *Error
+	require.True(t, errors.As(err, &remoteErr))
+	assert.Equal(t, http.StatusNotFound, remoteErr.StatusCode)
+
+	counter, ok := counts.Load("/missing" + pathExists)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), counter.(*atomic.Int64).Load())
+}
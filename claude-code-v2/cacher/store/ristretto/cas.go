@@ -0,0 +1,66 @@
+package ristretto
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// GetVersioned 获取单个值及其版本
+func (s *Store) GetVersioned(ctx context.Context, key string, dst interface{}) (store.Version, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	item, found := s.cache.Get(key)
+	if !found || item.isExpired() {
+		return store.NoVersion, false, nil
+	}
+
+	if err := s.copyValue(item.Value, dst); err != nil {
+		return store.NoVersion, false, fmt.Errorf("failed to copy value: %w", err)
+	}
+
+	return formatVersion(item.version), true, nil
+}
+
+// SetIfVersion 在写锁内比较版本，一致时写入新值
+func (s *Store) SetIfVersion(ctx context.Context, key string, value interface{}, version store.Version, ttl time.Duration) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := store.NoVersion
+	if item, found := s.cache.Get(key); found && !item.isExpired() {
+		current = formatVersion(item.version)
+	}
+	if current != version {
+		return false, nil
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	item := &cacheItem{
+		Value:     value,
+		ExpiresAt: expiresAt,
+		key:       key,
+	}
+	if err := s.setItem(item, ttl); err != nil {
+		return false, err
+	}
+
+	s.cache.Wait()
+	return true, nil
+}
+
+// formatVersion 将内部版本号转换为store.Version
+func formatVersion(version uint64) store.Version {
+	return store.Version(strconv.FormatUint(version, 10))
+}
+
+// 确保Store实现了store.CAS接口
+var _ store.CAS = (*Store)(nil)
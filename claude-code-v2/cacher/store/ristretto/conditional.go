@@ -0,0 +1,74 @@
+package ristretto
+
+import (
+	"context"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// MSetNX 在写锁内仅写入不存在的键
+func (s *Store) MSetNX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	return s.msetIf(items, func(string) time.Duration { return ttl }, false)
+}
+
+// MSetXX 在写锁内仅写入已存在的键
+func (s *Store) MSetXX(ctx context.Context, items map[string]interface{}, ttl time.Duration) (map[string]bool, error) {
+	return s.msetIf(items, func(string) time.Duration { return ttl }, true)
+}
+
+// MSetEntriesNX 在写锁内仅写入不存在的键，每个键使用各自的过期时间
+func (s *Store) MSetEntriesNX(ctx context.Context, entries []store.Entry) (map[string]bool, error) {
+	// 同一个键出现多次时以最后一个为准
+	items := make(map[string]interface{}, len(entries))
+	ttls := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		items[entry.Key] = entry.Value
+		ttls[entry.Key] = entry.TTL
+	}
+	return s.msetIf(items, func(key string) time.Duration { return ttls[key] }, false)
+}
+
+// msetIf 写入存在状态与exists一致的键
+// ttl: 返回每个键的过期时间
+func (s *Store) msetIf(items map[string]interface{}, ttl func(key string) time.Duration, exists bool) (map[string]bool, error) {
+	result := make(map[string]bool, len(items))
+
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer s.cache.Wait()
+
+	now := time.Now()
+	for key, value := range items {
+		current, found := s.cache.Get(key)
+		if (found && !current.isExpired()) != exists {
+			result[key] = false
+			continue
+		}
+
+		keyTTL := ttl(key)
+		var expiresAt time.Time
+		if keyTTL > 0 {
+			expiresAt = now.Add(keyTTL)
+		}
+
+		item := &cacheItem{
+			Value:     value,
+			ExpiresAt: expiresAt,
+			key:       key,
+		}
+		if err := s.setItem(item, keyTTL); err != nil {
+			return nil, err
+		}
+		result[key] = true
+	}
+
+	return result, nil
+}
+
+// 确保Store实现了store.ConditionalSetter接口
+var _ store.ConditionalSetter = (*Store)(nil)
@@ -0,0 +1,146 @@
+package ristretto
+
+import (
+	"reflect"
+	"sync"
+)
+
+// CostFunc 计算缓存项的cost，所有缓存项的cost之和不超过Options.MaxCost
+// key: 键名
+// value: 写入的值
+type CostFunc func(key string, value interface{}) int64
+
+// itemOverhead 每个缓存项除键和值以外的固定开销，即cacheItem本身的大小
+var itemOverhead = int64(reflect.TypeFor[cacheItem]().Size())
+
+// DefaultCostFunc 默认的CostFunc，以字节为单位估算键、值和缓存项本身占用的内存
+func DefaultCostFunc(key string, value interface{}) int64 {
+	return itemOverhead + int64(len(key)) + EstimateSize(value)
+}
+
+// fixedTypes 缓存类型是否为定长类型(不含指针、字符串、切片、map等引用数据)
+var fixedTypes sync.Map
+
+// EstimateSize 通过反射估算值占用的内存字节数，包括其引用的字符串、切片、map和指针指向的数据
+// 定长类型直接返回类型大小，判断结果按类型缓存；估算不包含内存分配器和map桶的额外开销
+func EstimateSize(value interface{}) int64 {
+	if value == nil {
+		return 0
+	}
+
+	v := reflect.ValueOf(value)
+	if isFixed(v.Type()) {
+		return int64(v.Type().Size())
+	}
+
+	e := estimator{visited: make(map[uintptr]struct{})}
+	return int64(v.Type().Size()) + e.referenced(v)
+}
+
+// isFixed 判断类型是否为定长类型，结果按类型缓存
+func isFixed(t reflect.Type) bool {
+	if fixed, ok := fixedTypes.Load(t); ok {
+		return fixed.(bool)
+	}
+
+	var fixed bool
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		fixed = true
+	case reflect.Array:
+		fixed = isFixed(t.Elem())
+	case reflect.Struct:
+		fixed = true
+		for i := 0; i < t.NumField(); i++ {
+			if !isFixed(t.Field(i).Type) {
+				fixed = false
+				break
+			}
+		}
+	}
+
+	fixedTypes.Store(t, fixed)
+	return fixed
+}
+
+// estimator 单次估算的状态，记录已经计入的指针以处理共享和循环引用
+type estimator struct {
+	visited map[uintptr]struct{}
+}
+
+// referenced 返回v引用的、不在v本身中的数据大小
+func (e *estimator) referenced(v reflect.Value) int64 {
+	if isFixed(v.Type()) {
+		return 0
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Slice:
+		if v.IsNil() || !e.visit(v.Pointer()) {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += e.referenced(v.Index(i))
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += e.referenced(v.Index(i))
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() || !e.visit(v.Pointer()) {
+			return 0
+		}
+		entrySize := int64(v.Type().Key().Size() + v.Type().Elem().Size())
+		size := int64(v.Len()) * entrySize
+		entries := v.MapRange()
+		for entries.Next() {
+			size += e.referenced(entries.Key()) + e.referenced(entries.Value())
+		}
+		return size
+
+	case reflect.Pointer:
+		if v.IsNil() || !e.visit(v.Pointer()) {
+			return 0
+		}
+		return int64(v.Type().Elem().Size()) + e.referenced(v.Elem())
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + e.referenced(elem)
+
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += e.referenced(v.Field(i))
+		}
+		return size
+
+	default:
+		// chan、func、unsafe.Pointer无法估算其引用的数据
+		return 0
+	}
+}
+
+// visit 记录指针，已经计入过时返回false
+func (e *estimator) visit(ptr uintptr) bool {
+	if _, ok := e.visited[ptr]; ok {
+		return false
+	}
+	e.visited[ptr] = struct{}{}
+	return true
+}
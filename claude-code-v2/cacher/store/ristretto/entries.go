@@ -0,0 +1,42 @@
+package ristretto
+
+import (
+	"context"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// MSetEntries 在写锁内逐个写入键值对，每个键使用各自的过期时间
+func (s *Store) MSetEntries(ctx context.Context, entries []store.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for _, entry := range entries {
+		var expiresAt time.Time
+		if entry.TTL > 0 {
+			expiresAt = now.Add(entry.TTL)
+		}
+
+		item := &cacheItem{
+			Value:     entry.Value,
+			ExpiresAt: expiresAt,
+			key:       entry.Key,
+		}
+		if err := s.setItem(item, entry.TTL); err != nil {
+			return err
+		}
+	}
+
+	// 等待写入完成
+	s.cache.Wait()
+	return nil
+}
+
+// 确保Store实现了store.EntrySetter接口
+var _ store.EntrySetter = (*Store)(nil)
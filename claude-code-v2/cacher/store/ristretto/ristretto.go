@@ -3,8 +3,11 @@ package ristretto
 import (
 	"context"
 	"fmt"
+	"iter"
 	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/ristretto/v2"
@@ -15,6 +18,12 @@ import (
 type cacheItem struct {
 	Value     interface{}
 	ExpiresAt time.Time
+
+	// key 原始键，ristretto淘汰回调只提供键的哈希，需要据此维护键索引
+	key string
+
+	// version 值的版本，写入新值时由setItem分配，只修改过期时间时保持不变
+	version uint64
 }
 
 // isExpired 检查缓存项是否过期
@@ -22,43 +31,160 @@ func (ci *cacheItem) isExpired() bool {
 	return !ci.ExpiresAt.IsZero() && time.Now().After(ci.ExpiresAt)
 }
 
+const (
+	defaultNumCounters = 1e7     // 10M 计数器数量
+	defaultMaxCost     = 1 << 30 // 1GB 最大内存使用
+	defaultBufferItems = 64      // 缓冲区大小
+)
+
+// Options Ristretto Store配置
+type Options struct {
+	// NumCounters 记录访问频率的计数器数量，建议为预计缓存项数量的10倍，0使用默认值1e7
+	NumCounters int64
+
+	// MaxCost 所有缓存项cost之和的上限，超出时淘汰缓存项，0使用默认值1GB
+	// 使用默认的CostFunc时单位为字节
+	MaxCost int64
+
+	// BufferItems 每个Get缓冲区的大小，0使用默认值64
+	BufferItems int64
+
+	// Metrics 启用ristretto的统计指标，Stats在未启用时除Keys外均为0
+	Metrics bool
+
+	// CostFunc 计算缓存项的cost，为nil时使用DefaultCostFunc按内存占用估算
+	CostFunc CostFunc
+
+	// IgnoreInternalCost 不在cost中计入ristretto内部每项约几十字节的开销
+	// CostFunc按缓存项数量而非字节计算cost时应当启用
+	IgnoreInternalCost bool
+
+	// Codec 快照和读取到不同类型时使用的编解码器，为nil时使用store.DefaultCodec
+	Codec store.Codec
+
+	// WatchBuffer Watch每个订阅者的缓冲区大小，0使用store.DefaultWatchBuffer
+	WatchBuffer int
+}
+
 // Store Ristretto实现的Store接口
 type Store struct {
-	cache *ristretto.Cache[string, *cacheItem]
-	mutex sync.RWMutex
+	cache    *ristretto.Cache[string, *cacheItem]
+	mutex    sync.RWMutex
+	codec    store.Codec
+	costFunc CostFunc
+
+	// index 键索引，ristretto不支持遍历，快照等需要枚举键的功能依赖该索引
+	// 被淘汰或拒绝的项通过回调移除，使用独立的锁避免回调与MSet互相等待
+	indexMutex sync.Mutex
+	index      map[string]*cacheItem
+
+	snapshots *snapshotter
+	// restoreDropped 恢复快照时写入失败而丢弃的缓存项数量
+	restoreDropped atomic.Uint64
+
+	// counters 计数器的分片锁
+	counters counterLocks
+
+	// versions 全局递增的版本号，删除后重新写入的键也不会得到重复的版本
+	versions atomic.Uint64
+
+	// evictHandlers OnEvict注册的回调
+	evictHandlers store.EvictHandlers
+
+	// watchers Watch的订阅者
+	watchers *store.WatchHub
 }
 
-// NewStore 创建新的Ristretto Store实例
+// NewStore 使用默认配置创建新的Ristretto Store实例
 func NewStore() (*Store, error) {
+	return NewStoreWithOptions(nil)
+}
+
+// NewStoreWithOptions 使用指定配置创建新的Ristretto Store实例
+// opts: 配置项，可以为nil使用默认配置
+func NewStoreWithOptions(opts *Options) (*Store, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.NumCounters == 0 {
+		o.NumCounters = defaultNumCounters
+	}
+	if o.MaxCost == 0 {
+		o.MaxCost = defaultMaxCost
+	}
+	if o.BufferItems == 0 {
+		o.BufferItems = defaultBufferItems
+	}
+	if o.CostFunc == nil {
+		o.CostFunc = DefaultCostFunc
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+
+	s := &Store{
+		codec:    o.Codec,
+		costFunc: o.CostFunc,
+		index:    make(map[string]*cacheItem),
+		watchers: &store.WatchHub{BufferSize: o.WatchBuffer},
+	}
+
 	cache, err := ristretto.NewCache(&ristretto.Config[string, *cacheItem]{
-		NumCounters: 1e7,     // 10M 计数器数量
-		MaxCost:     1 << 30, // 1GB 最大内存使用
-		BufferItems: 64,      // 缓冲区大小
+		NumCounters:        o.NumCounters,
+		MaxCost:            o.MaxCost,
+		BufferItems:        o.BufferItems,
+		Metrics:            o.Metrics,
+		IgnoreInternalCost: o.IgnoreInternalCost,
+		OnEvict:            s.onRemove,
+		OnReject:           s.onRemove,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ristretto cache: %w", err)
 	}
+	s.cache = cache
 
-	return &Store{
-		cache: cache,
-	}, nil
+	return s, nil
 }
 
-// Close 关闭缓存
-func (s *Store) Close() {
+// Close 关闭缓存，启用了定期快照时会先写入最后一次快照
+func (s *Store) Close() error {
+	var err error
+	if s.snapshots != nil {
+		if err = s.snapshots.stop(); err != nil {
+			err = fmt.Errorf("failed to write final snapshot: %w", err)
+		}
+	}
 	s.cache.Close()
+	return err
+}
+
+// Flush 清空所有缓存项
+func (s *Store) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// 先清空索引，Clear触发的淘汰回调找不到对应的项，因此不产生事件
+	s.indexMutex.Lock()
+	s.index = make(map[string]*cacheItem)
+	s.indexMutex.Unlock()
+
+	s.cache.Clear()
+
+	return nil
 }
 
 // Get 从缓存获取单个值
 func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
 	item, found := s.cache.Get(key)
 	if !found {
+		s.expireMissing(key)
 		return false, nil
 	}
 
 	// 检查是否过期
 	if item.isExpired() {
-		s.cache.Del(key)
+		s.deleteItem(item, store.EvictExpired)
 		return false, nil
 	}
 
@@ -94,24 +220,27 @@ func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) err
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
+	batchErr := &store.BatchError{}
 	for _, key := range keys {
 		item, found := s.cache.Get(key)
 		if !found {
+			s.expireMissing(key)
 			continue
 		}
 
 		// 检查是否过期
 		if item.isExpired() {
-			s.cache.Del(key)
+			s.deleteItem(item, store.EvictExpired)
 			continue
 		}
 
 		// 创建值类型的新实例
 		valuePtr := reflect.New(valueType)
 		
-		// 复制值
+		// 复制值，值本身不会损坏，失败只可能是类型不匹配，因此只记录不删除
 		if err := s.copyValue(item.Value, valuePtr.Interface()); err != nil {
-			return fmt.Errorf("failed to copy value for key %s: %w", key, err)
+			batchErr.Add(key, fmt.Errorf("failed to copy value: %w", err))
+			continue
 		}
 
 		// 设置到map中
@@ -119,7 +248,7 @@ func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) err
 		mapValue.SetMapIndex(keyValue, valuePtr.Elem())
 	}
 
-	return nil
+	return batchErr.Err()
 }
 
 // Exists 批量检查键存在性
@@ -138,8 +267,10 @@ func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, err
 		if found && !item.isExpired() {
 			result[key] = true
 		} else {
-			if found && item.isExpired() {
-				s.cache.Del(key)
+			if !found {
+				s.expireMissing(key)
+			} else if item.isExpired() {
+				s.deleteItem(item, store.EvictExpired)
 			}
 			result[key] = false
 		}
@@ -166,18 +297,11 @@ func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time
 		item := &cacheItem{
 			Value:     value,
 			ExpiresAt: expiresAt,
+			key:       key,
 		}
 
-		// 使用值的大小作为cost，对于简单类型使用1
-		cost := int64(1)
-		if ttl > 0 {
-			// 对于有TTL的项，设置更高的cost以避免被过早淘汰
-			cost = 2
-		}
-
-		success := s.cache.SetWithTTL(key, item, cost, ttl)
-		if !success {
-			return fmt.Errorf("failed to set key %s in cache", key)
+		if err := s.setItem(item, ttl); err != nil {
+			return err
 		}
 	}
 
@@ -197,9 +321,9 @@ func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
 
 	var deletedCount int64
 	for _, key := range keys {
-		_, found := s.cache.Get(key)
+		item, found := s.cache.Get(key)
 		if found {
-			s.cache.Del(key)
+			s.deleteItem(item, removeReason(item, store.EvictDeleted))
 			deletedCount++
 		}
 	}
@@ -207,8 +331,249 @@ func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
 	return deletedCount, nil
 }
 
+// setItem 写入缓存项并加入键索引，调用方需要持有写锁(计数器为读锁加分片锁)并在之后调用cache.Wait
+func (s *Store) setItem(item *cacheItem, ttl time.Duration) error {
+	if item.version == 0 {
+		item.version = s.versions.Add(1)
+	}
+
+	// cost至少为1，否则ristretto会调用自身的Cost回调
+	cost := max(s.costFunc(item.key, item.Value), 1)
+
+	// 先加入索引，写入被异步拒绝时由回调移除
+	s.indexMutex.Lock()
+	old := s.index[item.key]
+	s.index[item.key] = item
+	s.indexMutex.Unlock()
+
+	success := s.cache.SetWithTTL(item.key, item, cost, ttl)
+	if !success {
+		// 写入被丢弃，旧值仍在缓存中，恢复其索引且不产生事件
+		s.indexMutex.Lock()
+		if s.index[item.key] == item {
+			if old != nil {
+				s.index[item.key] = old
+			} else {
+				delete(s.index, item.key)
+			}
+		}
+		s.indexMutex.Unlock()
+		return fmt.Errorf("failed to set key %s in cache", item.key)
+	}
+
+	// 只修改过期时间时版本不变，不算覆盖
+	if old != nil && old.version != item.version {
+		s.emit(old, removeReason(old, store.EvictReplaced))
+	}
+	if old == nil || old.version != item.version {
+		s.watchers.Publish(store.WatchEvent{Key: item.key, Type: store.WatchSet})
+	}
+
+	return nil
+}
+
+// deleteItem 删除缓存项并移出键索引，缓存项仍在索引中时以reason产生事件
+func (s *Store) deleteItem(item *cacheItem, reason store.EvictReason) {
+	s.cache.Del(item.key)
+	if s.unindex(item) {
+		s.emit(item, reason)
+	}
+}
+
+// expireMissing 处理ristretto未找到的键
+// ristretto读取已过期的项时直接返回未找到，此时键可能仍在索引中，
+// 在ristretto定期清理之前就将其移出索引并产生过期事件
+func (s *Store) expireMissing(key string) {
+	s.indexMutex.Lock()
+	item := s.index[key]
+	s.indexMutex.Unlock()
+
+	if item != nil && item.isExpired() {
+		s.deleteItem(item, store.EvictExpired)
+	}
+}
+
+// onRemove ristretto淘汰或拒绝缓存项时的回调，包括ristretto定期清理过期项
+func (s *Store) onRemove(item *ristretto.Item[*cacheItem]) {
+	if item.Value != nil && s.unindex(item.Value) {
+		s.emit(item.Value, removeReason(item.Value, store.EvictCapacity))
+	}
+}
+
+// unindex 将缓存项移出键索引，键已被新值覆盖时保留索引
+// 返回: 缓存项是否在索引中并被移除
+func (s *Store) unindex(item *cacheItem) bool {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+
+	if s.index[item.key] != item {
+		return false
+	}
+	delete(s.index, item.key)
+	return true
+}
+
+// removeReason 缓存项已过期时返回EvictExpired，否则返回reason
+func removeReason(item *cacheItem, reason store.EvictReason) store.EvictReason {
+	if item.isExpired() {
+		return store.EvictExpired
+	}
+	return reason
+}
+
+// OnEvict 注册键离开缓存时的回调
+// 报告所有原因：ristretto按容量淘汰或拒绝写入(Capacity)、读取时或ristretto定期清理时发现过期(Expired)、
+// Del(Deleted)以及写入新值(Replaced)，Value为离开缓存的值
+func (s *Store) OnEvict(fn func(store.Event)) {
+	s.evictHandlers.Add(fn)
+}
+
+// emit 产生缓存项离开缓存的事件，被覆盖的键已经在写入时产生了Watch事件
+func (s *Store) emit(item *cacheItem, reason store.EvictReason) {
+	s.evictHandlers.Emit(store.Event{Key: item.key, Reason: reason, Value: item.Value})
+
+	switch reason {
+	case store.EvictExpired:
+		s.watchers.Publish(store.WatchEvent{Key: item.key, Type: store.WatchExpire})
+	case store.EvictDeleted, store.EvictCapacity:
+		s.watchers.Publish(store.WatchEvent{Key: item.key, Type: store.WatchDelete})
+	}
+}
+
+// Watch 订阅匹配pattern的键的变化，事件在写入方的goroutine中扇出到各订阅者的有界缓冲区
+// 容量淘汰报告为WatchDelete，过期在读取时或ristretto定期清理时报告；
+// 订阅者处理过慢时丢弃事件，丢弃数量见WatchEvent.Missed和Stats.WatchDropped
+func (s *Store) Watch(ctx context.Context, pattern string) (<-chan store.WatchEvent, error) {
+	return s.watchers.Add(ctx, pattern).Events(), nil
+}
+
+// indexedKeys 返回键索引中所有键的副本
+func (s *Store) indexedKeys() []string {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+
+	keys := make([]string, 0, len(s.index))
+	for key := range s.index {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Scan 基于键索引遍历匹配pattern的未过期键
+// 遍历开始时对键排序并取快照，cursor为起始偏移量，count在内存实现中不起作用
+func (s *Store) Scan(ctx context.Context, pattern string, cursor uint64, count int64) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		keys := s.indexedKeys()
+		slices.Sort(keys)
+		if cursor >= uint64(len(keys)) {
+			return
+		}
+
+		for _, key := range keys[cursor:] {
+			if err := ctx.Err(); err != nil {
+				yield("", err)
+				return
+			}
+			if !store.MatchPattern(pattern, key) {
+				continue
+			}
+			item, found := s.cache.Get(key)
+			if !found || item.isExpired() {
+				continue
+			}
+			if !yield(key, nil) {
+				return
+			}
+		}
+	}
+}
+
+// TTL 批量读取键的剩余过期时间
+func (s *Store) TTL(ctx context.Context, keys []string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, key := range keys {
+		item, found := s.cache.Get(key)
+		if !found || item.isExpired() {
+			continue
+		}
+		if item.ExpiresAt.IsZero() {
+			result[key] = store.NoTTL
+			continue
+		}
+		if remaining := time.Until(item.ExpiresAt); remaining > 0 {
+			result[key] = remaining
+		}
+	}
+
+	return result, nil
+}
+
+// Expire 为已存在的键设置新的过期时间
+// ristretto的TTL只能在写入时指定，因此以新的过期时间重新写入原值
+func (s *Store) Expire(ctx context.Context, keys []string, ttl time.Duration) (int64, error) {
+	if ttl <= 0 {
+		return 0, fmt.Errorf("ttl must be positive")
+	}
+
+	return s.resetExpiry(keys, ttl, func(item *cacheItem) bool { return true })
+}
+
+// Persist 移除键的过期时间
+func (s *Store) Persist(ctx context.Context, keys []string) (int64, error) {
+	return s.resetExpiry(keys, 0, func(item *cacheItem) bool { return !item.ExpiresAt.IsZero() })
+}
+
+// resetExpiry 以新的TTL重新写入满足filter的未过期键，返回写入的数量
+func (s *Store) resetExpiry(keys []string, ttl time.Duration, filter func(item *cacheItem) bool) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer s.cache.Wait()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	var count int64
+	for _, key := range keys {
+		item, found := s.cache.Get(key)
+		if !found || item.isExpired() || !filter(item) {
+			continue
+		}
+
+		updated := &cacheItem{
+			Value:     item.Value,
+			ExpiresAt: expiresAt,
+			key:       key,
+			version:   item.version,
+		}
+		if err := s.setItem(updated, ttl); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
 // copyValue 复制值，处理不同类型的复制逻辑
 func (s *Store) copyValue(src, dst interface{}) error {
+	// 从快照恢复的值按目标类型解码
+	if encoded, ok := src.(encodedValue); ok {
+		if err := s.codec.Unmarshal(encoded, dst); err != nil {
+			return fmt.Errorf("failed to decode restored value: %w", err)
+		}
+		return nil
+	}
+
 	// 处理源值为nil的情况
 	if src == nil {
 		dstValue := reflect.ValueOf(dst)
@@ -246,5 +611,13 @@ func (s *Store) copyValue(src, dst interface{}) error {
 	return fmt.Errorf("cannot copy value of type %T to %T", src, dst)
 }
 
-// 确保Store实现了store.Store接口
-var _ store.Store = (*Store)(nil)
+// 确保Store实现了store.Store及各可选接口
+var (
+	_ store.Store         = (*Store)(nil)
+	_ store.Closer        = (*Store)(nil)
+	_ store.Flusher       = (*Store)(nil)
+	_ store.Scanner       = (*Store)(nil)
+	_ store.Expirer       = (*Store)(nil)
+	_ store.EvictNotifier = (*Store)(nil)
+	_ store.Watcher       = (*Store)(nil)
+)
@@ -1,8 +1,18 @@
 package ristretto
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go-cache/cacher/store"
 )
@@ -16,4 +26,625 @@ func TestRistrettoStore(t *testing.T) {
 	// 运行通用测试套件
 	testHelper := store.NewTestHelper(t, ristrettoStore)
 	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+	testHelper.TestEvictNotifier()
+	testHelper.TestWatcher()
+}
+
+type snapshotUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestRistrettoStoreSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	source, err := NewStore()
+	require.NoError(t, err)
+	defer source.Close()
+
+	require.NoError(t, source.MSet(ctx, map[string]interface{}{
+		"user":  snapshotUser{Name: "Alice", Age: 30},
+		"count": 42,
+		"raw":   []byte("bytes"),
+	}, 0))
+	require.NoError(t, source.MSet(ctx, map[string]interface{}{"session": "s1"}, time.Hour))
+	require.NoError(t, source.MSet(ctx, map[string]interface{}{"short": "gone"}, 50*time.Millisecond))
+
+	var buf bytes.Buffer
+	require.NoError(t, source.Snapshot(&buf))
+
+	// 已过期的项不会被恢复
+	time.Sleep(100 * time.Millisecond)
+
+	target, err := NewStore()
+	require.NoError(t, err)
+	defer target.Close()
+	require.NoError(t, target.Restore(&buf))
+
+	var user snapshotUser
+	found, err := target.Get(ctx, "user", &user)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, snapshotUser{Name: "Alice", Age: 30}, user)
+
+	counts := make(map[string]int)
+	require.NoError(t, target.MGet(ctx, []string{"count"}, &counts))
+	assert.Equal(t, map[string]int{"count": 42}, counts)
+
+	var raw []byte
+	found, err = target.Get(ctx, "raw", &raw)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("bytes"), raw)
+
+	exists, err := target.Exists(ctx, []string{"session", "short"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"session": true, "short": false}, exists)
+
+	// 剩余TTL被保留
+	target.mutex.RLock()
+	item, _ := target.cache.Get("session")
+	target.mutex.RUnlock()
+	assert.WithinDuration(t, time.Now().Add(time.Hour), item.ExpiresAt, time.Minute)
+
+	// 恢复的值可以再次写入快照
+	var again bytes.Buffer
+	require.NoError(t, target.Snapshot(&again))
+	restored, err := NewStore()
+	require.NoError(t, err)
+	defer restored.Close()
+	require.NoError(t, restored.Restore(&again))
+	found, err = restored.Get(ctx, "user", &user)
+	require.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestRistrettoStoreRestoreCorrupt(t *testing.T) {
+	ctx := context.Background()
+	source, err := NewStore()
+	require.NoError(t, err)
+	defer source.Close()
+
+	require.NoError(t, source.MSet(ctx, map[string]interface{}{"a": "1", "b": "2"}, 0))
+
+	var buf bytes.Buffer
+	require.NoError(t, source.Snapshot(&buf))
+	full := buf.Bytes()
+
+	// 截断最后一条记录
+	target, err := NewStore()
+	require.NoError(t, err)
+	defer target.Close()
+	require.NoError(t, target.Restore(bytes.NewReader(full[:len(full)-2])))
+	exists, err := target.Exists(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countTrue(exists))
+
+	// 最后一条记录校验失败
+	corrupt := append([]byte{}, full...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	target, err = NewStore()
+	require.NoError(t, err)
+	defer target.Close()
+	require.NoError(t, target.Restore(bytes.NewReader(corrupt)))
+	exists, err = target.Exists(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, countTrue(exists))
+
+	// 文件头错误时返回错误
+	assert.Error(t, target.Restore(bytes.NewReader([]byte("XXXX\x01"))))
+	assert.Error(t, target.Restore(bytes.NewReader([]byte("GCSN\x09"))))
+
+	// 损坏的长度字段不会按其声明的大小分配内存
+	huge := append([]byte("GCSN\x01"), 0x1f, 0xff, 0xff, 0xff, 0, 0, 0, 0)
+	huge = append(huge, make([]byte, 1024)...)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	require.NoError(t, target.Restore(bytes.NewReader(huge)))
+	runtime.ReadMemStats(&after)
+	assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+}
+
+func TestRistrettoStoreRestoreDropped(t *testing.T) {
+	ctx := context.Background()
+	source, err := NewStore()
+	require.NoError(t, err)
+	defer source.Close()
+
+	require.NoError(t, source.MSet(ctx, map[string]interface{}{"a": "1", "b": "2", "c": "3"}, 0))
+	var buf bytes.Buffer
+	require.NoError(t, source.Snapshot(&buf))
+
+	// 已关闭的ristretto拒绝所有写入，每一项都被丢弃但不中断恢复
+	target, err := NewStore()
+	require.NoError(t, err)
+	require.NoError(t, target.Close())
+	require.NoError(t, target.Restore(&buf))
+	assert.Equal(t, uint64(3), target.Stats().RestoreDropped)
+}
+
+func TestRistrettoStoreEnableSnapshots(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	first, err := NewStore()
+	require.NoError(t, err)
+	require.NoError(t, first.EnableSnapshots(SnapshotOptions{Path: path, Interval: 20 * time.Millisecond}))
+	require.NoError(t, first.MSet(ctx, map[string]interface{}{"k": "v"}, time.Hour))
+
+	// 定期写入快照
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// Close时写入最后一次快照
+	require.NoError(t, first.MSet(ctx, map[string]interface{}{"last": "write"}, 0))
+	first.Close()
+
+	second, err := NewStore()
+	require.NoError(t, err)
+	defer second.Close()
+	require.NoError(t, second.EnableSnapshots(SnapshotOptions{Path: path}))
+
+	exists, err := second.Exists(ctx, []string{"k", "last"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"k": true, "last": true}, exists)
+}
+
+// countTrue 统计存在的键数量
+func countTrue(exists map[string]bool) int {
+	count := 0
+	for _, ok := range exists {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRistrettoStoreScan(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{
+		"user:1": 1, "user:2": 2, "user:3": 3, "order:1": 1,
+	}, 0))
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"user:short": 4}, 50*time.Millisecond))
+	_, err = ristrettoStore.Del(ctx, "user:3")
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	var keys []string
+	for key, err := range ristrettoStore.Scan(ctx, "user:*", 0, 0) {
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	// 已删除和已过期的键不会出现，结果按键排序
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+
+	// cursor为排序后键列表中的偏移量
+	keys = nil
+	for key, err := range ristrettoStore.Scan(ctx, "", 2, 0) {
+		require.NoError(t, err)
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"user:2"}, keys)
+
+	// ctx取消时返回错误
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for _, err := range ristrettoStore.Scan(canceled, "", 0, 0) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestRistrettoStoreExpirer(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	// 运行Expirer测试
+	store.NewTestHelper(t, ristrettoStore).TestExpirer()
+
+	// 缩短TTL后键按新的时间过期
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"k": "v"}, time.Hour))
+	_, err = ristrettoStore.Expire(ctx, []string{"k"}, 50*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	exists, err := ristrettoStore.Exists(ctx, []string{"k"})
+	require.NoError(t, err)
+	assert.False(t, exists["k"])
+}
+
+func TestRistrettoStoreCounter(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	// 运行Counter测试
+	store.NewTestHelper(t, ristrettoStore).TestCounter()
+
+	// 并发增加不丢失更新
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, err := ristrettoStore.IncrBy(ctx, "concurrent", 1, 0)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	value, err := ristrettoStore.IncrBy(ctx, "concurrent", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), value)
+
+	// 溢出返回错误
+	_, err = ristrettoStore.IncrBy(ctx, "concurrent", math.MaxInt64, 0)
+	assert.Error(t, err)
+
+	// 从快照恢复的计数器可以继续累加
+	var buf bytes.Buffer
+	require.NoError(t, ristrettoStore.Snapshot(&buf))
+	restored, err := NewStore()
+	require.NoError(t, err)
+	defer restored.Close()
+	require.NoError(t, restored.Restore(&buf))
+
+	value, err = restored.IncrBy(ctx, "concurrent", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1001), value)
+
+	// 恢复的值使用Store的Codec解码
+	rawStore, err := NewStoreWithOptions(&Options{Codec: store.RawCodec{}})
+	require.NoError(t, err)
+	defer rawStore.Close()
+	require.NoError(t, rawStore.MSet(ctx, map[string]interface{}{"raw": "0012"}, 0))
+	buf.Reset()
+	require.NoError(t, rawStore.Snapshot(&buf))
+	rawRestored, err := NewStoreWithOptions(&Options{Codec: store.RawCodec{}})
+	require.NoError(t, err)
+	defer rawRestored.Close()
+	require.NoError(t, rawRestored.Restore(&buf))
+
+	value, err = rawRestored.IncrBy(ctx, "raw", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), value)
+}
+
+func TestRistrettoStoreCAS(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	// 运行CAS测试
+	store.NewTestHelper(t, ristrettoStore).TestCAS()
+
+	// 只修改过期时间不改变版本
+	var result string
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"k": "v"}, time.Hour))
+	version, _, err := ristrettoStore.GetVersioned(ctx, "k", &result)
+	require.NoError(t, err)
+	_, err = ristrettoStore.Expire(ctx, []string{"k"}, time.Minute)
+	require.NoError(t, err)
+	set, err := ristrettoStore.SetIfVersion(ctx, "k", "v2", version, 0)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	// 计数器更新改变版本
+	_, err = ristrettoStore.IncrBy(ctx, "n", 1, 0)
+	require.NoError(t, err)
+	var n int64
+	version, _, err = ristrettoStore.GetVersioned(ctx, "n", &n)
+	require.NoError(t, err)
+	_, err = ristrettoStore.IncrBy(ctx, "n", 1, 0)
+	require.NoError(t, err)
+	set, err = ristrettoStore.SetIfVersion(ctx, "n", int64(0), version, 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+}
+
+func TestRistrettoStoreConditionalSetter(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	// 运行ConditionalSetter测试
+	store.NewTestHelper(t, ristrettoStore).TestConditionalSetter()
+
+	// 运行EntrySetter测试
+	store.NewTestHelper(t, ristrettoStore).TestEntrySetter()
+
+	// 并发MSetNX只有一个写入成功
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	winners := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			written, err := ristrettoStore.MSetNX(ctx, map[string]interface{}{"first": i}, 0)
+			assert.NoError(t, err)
+			if written["first"] {
+				mutex.Lock()
+				winners++
+				mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, winners)
+
+	// 过期的键视为不存在
+	require.NoError(t, ristrettoStore.MSet(ctx, map[string]interface{}{"short": "v"}, 50*time.Millisecond))
+	time.Sleep(100 * time.Millisecond)
+	written, err := ristrettoStore.MSetXX(ctx, map[string]interface{}{"short": "v2"}, 0)
+	require.NoError(t, err)
+	assert.False(t, written["short"])
+}
+
+func TestEstimateSize(t *testing.T) {
+	type fixed struct {
+		A int64
+		B [4]int32
+	}
+	type node struct {
+		Name string
+		Next *node
+	}
+
+	assert.Equal(t, int64(0), EstimateSize(nil))
+	assert.Equal(t, int64(8), EstimateSize(int64(1)))
+	assert.Equal(t, int64(24), EstimateSize(fixed{}))
+	assert.Equal(t, int64(16+5), EstimateSize("hello"))
+	assert.Equal(t, int64(24+100), EstimateSize(make([]byte, 10, 100)))
+	assert.Equal(t, int64(24+2*16+3+4), EstimateSize([]string{"abc", "defg"}))
+	assert.Equal(t, int64(8+2*(16+8)+1+1), EstimateSize(map[string]int64{"a": 1, "b": 2}))
+
+	// 循环引用只计入一次
+	n := &node{Name: "ab"}
+	n.Next = n
+	assert.Equal(t, int64(8+24+2), EstimateSize(n))
+
+	// 值越大cost越大
+	assert.Greater(t, DefaultCostFunc("k", make([]byte, 1024)), DefaultCostFunc("k", make([]byte, 16)))
+}
+
+func TestRistrettoStoreOptions(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStoreWithOptions(&Options{
+		NumCounters:        1000,
+		MaxCost:            10,
+		Metrics:            true,
+		IgnoreInternalCost: true,
+		CostFunc:           func(key string, value interface{}) int64 { return 1 },
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	for i := 0; i < 100; i++ {
+		_ = s.MSet(ctx, map[string]interface{}{fmt.Sprintf("key%d", i): i}, 0)
+	}
+
+	stats := s.Stats()
+	assert.Equal(t, int64(10), stats.MaxCost)
+	assert.LessOrEqual(t, stats.Keys, 10)
+	assert.LessOrEqual(t, stats.CostAdded-stats.CostEvicted, uint64(10))
+	assert.Greater(t, stats.KeysEvicted+stats.SetsRejected, uint64(0))
+
+	// 索引与缓存保持一致
+	var count int
+	for i := 0; i < 100; i++ {
+		var value int
+		found, err := s.Get(ctx, fmt.Sprintf("key%d", i), &value)
+		require.NoError(t, err)
+		if found {
+			count++
+		}
+	}
+	assert.LessOrEqual(t, count, 10)
+
+	stats = s.Stats()
+	assert.Equal(t, uint64(100), stats.Hits+stats.Misses)
+	assert.InDelta(t, float64(stats.Hits)/100, stats.HitRatio, 1e-9)
+}
+
+func TestRistrettoStoreStatsDisabled(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStore()
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"a": 1}, 0))
+	var value int
+	_, err = s.Get(ctx, "a", &value)
+	require.NoError(t, err)
+
+	stats := s.Stats()
+	assert.Equal(t, 1, stats.Keys)
+	assert.Equal(t, int64(1<<30), stats.MaxCost)
+	assert.Zero(t, stats.Hits)
+	assert.Zero(t, stats.HitRatio)
+}
+
+func TestRistrettoStoreOnEvict(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStore()
+	require.NoError(t, err)
+	defer s.Close()
+
+	var mutex sync.Mutex
+	var events []store.Event
+	s.OnEvict(func(event store.Event) {
+		mutex.Lock()
+		events = append(events, event)
+		mutex.Unlock()
+	})
+	takeEvents := func() []store.Event {
+		mutex.Lock()
+		defer mutex.Unlock()
+		taken := events
+		events = nil
+		return taken
+	}
+
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"a": "v1"}, 0))
+	assert.Empty(t, takeEvents())
+
+	// 覆盖
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"a": "v2"}, 0))
+	assert.Equal(t, []store.Event{{Key: "a", Reason: store.EvictReplaced, Value: "v1"}}, takeEvents())
+
+	// 只修改过期时间不算覆盖
+	_, err = s.Expire(ctx, []string{"a"}, time.Hour)
+	require.NoError(t, err)
+	_, err = s.Persist(ctx, []string{"a"})
+	require.NoError(t, err)
+	assert.Empty(t, takeEvents())
+
+	// 删除
+	_, err = s.Del(ctx, "a", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, []store.Event{{Key: "a", Reason: store.EvictDeleted, Value: "v2"}}, takeEvents())
+
+	// 读取时发现过期
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"b": "short"}, 20*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+	var value string
+	found, err := s.Get(ctx, "b", &value)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, []store.Event{{Key: "b", Reason: store.EvictExpired, Value: "short"}}, takeEvents())
+
+	// 过期的键被覆盖时报告为过期
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"c": "old"}, 20*time.Millisecond))
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"c": "new"}, 0))
+	assert.Equal(t, []store.Event{{Key: "c", Reason: store.EvictExpired, Value: "old"}}, takeEvents())
+
+	// Flush不产生事件
+	require.NoError(t, s.Flush(ctx))
+	s.cache.Wait()
+	assert.Empty(t, takeEvents())
+}
+
+func TestRistrettoStoreOnEvictCapacity(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStoreWithOptions(&Options{
+		NumCounters:        1000,
+		MaxCost:            10,
+		IgnoreInternalCost: true,
+		CostFunc:           func(key string, value interface{}) int64 { return 1 },
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	var mutex sync.Mutex
+	capacity := make(map[string]bool)
+	s.OnEvict(func(event store.Event) {
+		assert.Equal(t, store.EvictCapacity, event.Reason)
+		mutex.Lock()
+		capacity[event.Key] = true
+		mutex.Unlock()
+	})
+
+	dropped := 0
+	for i := 0; i < 100; i++ {
+		if err := s.MSet(ctx, map[string]interface{}{fmt.Sprintf("key%d", i): i}, 0); err != nil {
+			dropped++
+		}
+	}
+
+	// 每个键要么仍在缓存中，要么报告了一次容量淘汰，要么写入时返回了错误
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 100, len(capacity)+s.Stats().Keys+dropped)
+	for _, key := range s.indexedKeys() {
+		assert.False(t, capacity[key], key)
+	}
+}
+
+func TestRistrettoStoreSetDropped(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewStore()
+	require.NoError(t, err)
+
+	var events []store.Event
+	s.OnEvict(func(event store.Event) { events = append(events, event) })
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watch, err := s.Watch(watchCtx, "*")
+	require.NoError(t, err)
+
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"a": "v1"}, 0))
+	assert.Equal(t, store.WatchEvent{Key: "a", Type: store.WatchSet}, <-watch)
+	old := s.index["a"]
+
+	// 关闭后ristretto丢弃写入，索引仍指向旧值且不产生事件
+	// Close会淘汰所有项，重新放入旧值的索引以模拟写入被丢弃时旧值仍在缓存中
+	require.NoError(t, s.Close())
+	s.index["a"] = old
+	events = nil
+	for len(watch) > 0 {
+		<-watch
+	}
+	assert.Error(t, s.MSet(ctx, map[string]interface{}{"a": "v2", "b": "v"}, 0))
+	assert.Same(t, old, s.index["a"])
+	assert.Equal(t, []string{"a"}, s.indexedKeys())
+	assert.Empty(t, events)
+	select {
+	case event := <-watch:
+		t.Fatalf("unexpected watch event %+v", event)
+	default:
+	}
+}
+
+func TestRistrettoStoreWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := NewStoreWithOptions(&Options{WatchBuffer: 2})
+	require.NoError(t, err)
+	defer s.Close()
+
+	events, err := s.Watch(ctx, "user:*")
+	require.NoError(t, err)
+
+	// 只修改过期时间不产生事件
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"user:1": "a"}, 20*time.Millisecond))
+	_, err = s.Persist(ctx, []string{"user:1"})
+	require.NoError(t, err)
+	assert.Equal(t, store.WatchEvent{Key: "user:1", Type: store.WatchSet}, <-events)
+
+	_, err = s.Expire(ctx, []string{"user:1"}, 20*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+	var value string
+	found, err := s.Get(ctx, "user:1", &value)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, store.WatchEvent{Key: "user:1", Type: store.WatchExpire}, <-events)
+
+	// 订阅者处理过慢时丢弃事件而不阻塞写入
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.MSet(ctx, map[string]interface{}{fmt.Sprintf("user:%d", i): i}, 0))
+	}
+	assert.Equal(t, uint64(3), s.Stats().WatchDropped)
+
+	<-events
+	<-events
+	_, err = s.Del(ctx, "user:0")
+	require.NoError(t, err)
+	assert.Equal(t, store.WatchEvent{Key: "user:0", Type: store.WatchDelete, Missed: 3}, <-events)
 }
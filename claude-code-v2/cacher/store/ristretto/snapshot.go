@@ -0,0 +1,304 @@
+package ristretto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// 快照格式:
+//
+//	header: "GCSN" | version(1字节)
+//	record: bodyLen(uint32) | crc32(body)(uint32) | body
+//	body:   keyLen(uvarint) | key | expiresAt(varint, UnixNano, 0表示永不过期) | value
+//
+// 记录逐条写入，读取时遇到截断或校验失败的记录即停止，之前的记录仍然有效。
+// 过期时间以绝对时间保存，停机期间流逝的时间同样计入TTL。
+const (
+	snapshotMagic   = "GCSN"
+	snapshotVersion = 1
+
+	// maxSnapshotRecord 单条记录的最大字节数，超出视为数据损坏
+	maxSnapshotRecord = 512 << 20
+)
+
+// encodedValue 从快照恢复的值，保存编码后的字节，读取时按目标类型解码
+type encodedValue []byte
+
+// SnapshotOptions 定期快照配置
+type SnapshotOptions struct {
+	// Path 快照文件路径
+	Path string
+
+	// Interval 快照间隔，0使用默认值5分钟
+	Interval time.Duration
+}
+
+// snapshotter 定期快照的后台任务
+type snapshotter struct {
+	stopCh    chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// err 停止时最后一次快照的错误
+	err error
+}
+
+// Snapshot 将所有未过期的缓存项以流式格式写入w
+// 值使用Codec编码，无法编码的值(如函数、channel)会被跳过
+func (s *Store) Snapshot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(snapshotMagic)
+	bw.WriteByte(snapshotVersion)
+
+	var header [8]byte
+	body := make([]byte, 0, 256)
+	for _, key := range s.indexedKeys() {
+		item, found := s.cache.Get(key)
+		if !found || item.isExpired() {
+			continue
+		}
+
+		value, err := s.encodeValue(item.Value)
+		if err != nil {
+			// 缓存数据可以重建，跳过无法编码的值而不是放弃整个快照
+			continue
+		}
+
+		var expiresAt int64
+		if !item.ExpiresAt.IsZero() {
+			expiresAt = item.ExpiresAt.UnixNano()
+		}
+
+		body = binary.AppendUvarint(body[:0], uint64(len(key)))
+		body = append(body, key...)
+		body = binary.AppendVarint(body, expiresAt)
+		body = append(body, value...)
+
+		binary.BigEndian.PutUint32(header[0:4], uint32(len(body)))
+		binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(body))
+		bw.Write(header[:])
+		if _, err := bw.Write(body); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore 从r读取Snapshot写入的快照并加载到缓存，已存在的键会被覆盖
+// 已过期的项会被跳过；遇到截断或损坏的记录时停止读取，保留之前已加载的项。
+// 写入ristretto失败的项被丢弃并继续恢复其余的项，丢弃数量见Stats.RestoreDropped
+func (s *Store) Restore(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return fmt.Errorf("failed to read snapshot header: %w", err)
+	}
+	if string(magic[:len(snapshotMagic)]) != snapshotMagic {
+		return fmt.Errorf("invalid snapshot header")
+	}
+	if magic[len(snapshotMagic)] != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", magic[len(snapshotMagic)])
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer s.cache.Wait()
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return restoreEnd(err)
+		}
+
+		size := binary.BigEndian.Uint32(header[0:4])
+		if size > maxSnapshotRecord {
+			return nil
+		}
+		body, err := readRecordBody(br, size)
+		if err != nil {
+			return restoreEnd(err)
+		}
+		if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(header[4:8]) {
+			return nil
+		}
+
+		item, ok := decodeRecord(body)
+		if !ok {
+			return nil
+		}
+
+		var ttl time.Duration
+		if !item.ExpiresAt.IsZero() {
+			ttl = time.Until(item.ExpiresAt)
+			if ttl <= 0 {
+				continue
+			}
+		}
+
+		// 写入缓冲区已满时ristretto会丢弃写入，缓存允许缺失部分项，不放弃其余的项
+		if err := s.setItem(item, ttl); err != nil {
+			s.restoreDropped.Add(1)
+		}
+	}
+}
+
+// readRecordBody 读取size字节的记录体
+// 长度字段可能已损坏，缓冲区随实际读到的数据增长，不会按长度字段预先分配
+func readRecordBody(r io.Reader, size uint32) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(int(min(size, 64<<10)))
+	if _, err := io.CopyN(&buf, r, int64(size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SnapshotToFile 将快照原子地写入path：先写入同目录下的临时文件，再重命名
+func (s *Store) SnapshotToFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := s.Snapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync snapshot file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rename snapshot file: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreFromFile 从path加载快照，文件不存在时不做任何操作
+func (s *Store) RestoreFromFile(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer f.Close()
+
+	return s.Restore(f)
+}
+
+// EnableSnapshots 从opts.Path加载已有快照，并在后台按opts.Interval定期写入快照
+// Close时会再写入一次，使重启后的缓存尽可能完整
+func (s *Store) EnableSnapshots(opts SnapshotOptions) error {
+	if opts.Path == "" {
+		return fmt.Errorf("snapshot path is required")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Minute
+	}
+	if s.snapshots != nil {
+		return fmt.Errorf("snapshots already enabled")
+	}
+
+	if err := s.RestoreFromFile(opts.Path); err != nil {
+		return err
+	}
+
+	s.snapshots = &snapshotter{
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.runSnapshots(opts)
+
+	return nil
+}
+
+// runSnapshots 定期写入快照，停止时写入最后一次
+func (s *Store) runSnapshots(opts SnapshotOptions) {
+	defer close(s.snapshots.done)
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// 后台任务出错时等待下一轮重试
+			_ = s.SnapshotToFile(opts.Path)
+		case <-s.snapshots.stopCh:
+			s.snapshots.err = s.SnapshotToFile(opts.Path)
+			return
+		}
+	}
+}
+
+// stop 停止后台任务并等待最后一次快照完成
+// 返回: 最后一次快照的错误信息
+func (sn *snapshotter) stop() error {
+	sn.closeOnce.Do(func() {
+		close(sn.stopCh)
+		<-sn.done
+	})
+	return sn.err
+}
+
+// encodeValue 编码缓存值，从快照恢复且未被覆盖的值直接使用原始字节
+func (s *Store) encodeValue(value interface{}) ([]byte, error) {
+	if encoded, ok := value.(encodedValue); ok {
+		return encoded, nil
+	}
+	return s.codec.Marshal(value)
+}
+
+// decodeRecord 解析记录体
+func decodeRecord(body []byte) (*cacheItem, bool) {
+	keyLen, n := binary.Uvarint(body)
+	if n <= 0 || keyLen > uint64(len(body)-n) {
+		return nil, false
+	}
+	body = body[n:]
+	key := string(body[:keyLen])
+	body = body[keyLen:]
+
+	expiresAt, n := binary.Varint(body)
+	if n <= 0 {
+		return nil, false
+	}
+
+	item := &cacheItem{
+		Value: encodedValue(body[n:]),
+		key:   key,
+	}
+	if expiresAt != 0 {
+		item.ExpiresAt = time.Unix(0, expiresAt)
+	}
+	return item, true
+}
+
+// restoreEnd 处理读取记录时的错误：正常结束或尾部截断都视为读取完成
+func restoreEnd(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return fmt.Errorf("failed to read snapshot: %w", err)
+}
@@ -0,0 +1,75 @@
+package ristretto
+
+// Stats Ristretto Store的统计信息
+// 除Keys和WatchDropped外的指标来自ristretto，需要启用Options.Metrics，否则均为0
+// Store内部的查找(如MSetNX、Del前的检查)同样计入命中和未命中
+type Stats struct {
+	// Keys 当前索引中的键数量，包括尚未被清理的过期键
+	Keys int
+
+	// MaxCost cost上限
+	MaxCost int64
+
+	// Hits 命中次数
+	Hits uint64
+	// Misses 未命中次数
+	Misses uint64
+	// HitRatio 命中率，没有查找时为0
+	HitRatio float64
+
+	// KeysAdded 新增的键数量
+	KeysAdded uint64
+	// KeysUpdated 更新的键数量
+	KeysUpdated uint64
+	// KeysEvicted 被淘汰的键数量
+	KeysEvicted uint64
+
+	// CostAdded 累计加入的cost
+	CostAdded uint64
+	// CostEvicted 累计被淘汰的cost
+	CostEvicted uint64
+
+	// SetsDropped 因缓冲区已满被丢弃的写入次数
+	SetsDropped uint64
+	// SetsRejected 被准入策略拒绝的写入次数
+	SetsRejected uint64
+	// GetsDropped 因缓冲区已满未计入访问频率的读取次数
+	GetsDropped uint64
+	// GetsKept 计入访问频率的读取次数
+	GetsKept uint64
+
+	// WatchDropped Watch订阅者因处理过慢累计丢弃的事件数量，不需要启用Metrics
+	WatchDropped uint64
+
+	// RestoreDropped 恢复快照时因写入缓冲区已满等原因丢弃的缓存项数量，不需要启用Metrics
+	RestoreDropped uint64
+}
+
+// Stats 返回缓存的统计信息
+func (s *Store) Stats() Stats {
+	s.indexMutex.Lock()
+	keys := len(s.index)
+	s.indexMutex.Unlock()
+
+	// 未启用Metrics时为nil，其方法对nil返回0
+	m := s.cache.Metrics
+	return Stats{
+		Keys:         keys,
+		MaxCost:      s.cache.MaxCost(),
+		Hits:         m.Hits(),
+		Misses:       m.Misses(),
+		HitRatio:     m.Ratio(),
+		KeysAdded:    m.KeysAdded(),
+		KeysUpdated:  m.KeysUpdated(),
+		KeysEvicted:  m.KeysEvicted(),
+		CostAdded:    m.CostAdded(),
+		CostEvicted:  m.CostEvicted(),
+		SetsDropped:  m.SetsDropped(),
+		SetsRejected: m.SetsRejected(),
+		GetsDropped:  m.GetsDropped(),
+		GetsKept:     m.GetsKept(),
+		WatchDropped: s.watchers.Dropped(),
+
+		RestoreDropped: s.restoreDropped.Load(),
+	}
+}
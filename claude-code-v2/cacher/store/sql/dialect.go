@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect 数据库方言，屏蔽不同数据库在占位符、建表和upsert语法上的差异
+type Dialect interface {
+	// Placeholder 返回第i个参数(从1开始)的占位符
+	Placeholder(i int) string
+
+	// CreateTable 返回建表及建索引语句，需要可重复执行
+	CreateTable(table string) []string
+
+	// Upsert 返回插入或更新单行的语句，参数顺序为 键, 值, 过期时间
+	Upsert(table string) string
+
+	// MaxParams 单条语句允许的最大参数数量
+	MaxParams() int
+}
+
+var (
+	// Postgres PostgreSQL方言
+	Postgres Dialect = postgresDialect{}
+	// MySQL MySQL方言
+	MySQL Dialect = mysqlDialect{}
+	// SQLite SQLite方言
+	SQLite Dialect = sqliteDialect{}
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Placeholder(i int) string {
+	return fmt.Sprintf("$%d", i)
+}
+
+func (postgresDialect) CreateTable(table string) []string {
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (k VARCHAR(255) PRIMARY KEY, v BYTEA, expires_at BIGINT NOT NULL DEFAULT 0)", table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_expires_at_idx ON %s (expires_at)", table, table),
+	}
+}
+
+func (postgresDialect) Upsert(table string) string {
+	return fmt.Sprintf("INSERT INTO %s (k, v, expires_at) VALUES ($1, $2, $3) ON CONFLICT (k) DO UPDATE SET v = EXCLUDED.v, expires_at = EXCLUDED.expires_at", table)
+}
+
+func (postgresDialect) MaxParams() int {
+	return 65535
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Placeholder(i int) string {
+	return "?"
+}
+
+func (mysqlDialect) CreateTable(table string) []string {
+	// MySQL不支持CREATE INDEX IF NOT EXISTS，索引直接在建表语句中声明
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (k VARCHAR(255) NOT NULL PRIMARY KEY, v LONGBLOB, expires_at BIGINT NOT NULL DEFAULT 0, INDEX %s_expires_at_idx (expires_at))", table, table),
+	}
+}
+
+func (mysqlDialect) Upsert(table string) string {
+	return fmt.Sprintf("INSERT INTO %s (k, v, expires_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE v = VALUES(v), expires_at = VALUES(expires_at)", table)
+}
+
+func (mysqlDialect) MaxParams() int {
+	return 65535
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Placeholder(i int) string {
+	return "?"
+}
+
+func (sqliteDialect) CreateTable(table string) []string {
+	return []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (k TEXT PRIMARY KEY, v BLOB, expires_at INTEGER NOT NULL DEFAULT 0)", table),
+		fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_expires_at_idx ON %s (expires_at)", table, table),
+	}
+}
+
+func (sqliteDialect) Upsert(table string) string {
+	return fmt.Sprintf("INSERT INTO %s (k, v, expires_at) VALUES (?, ?, ?) ON CONFLICT (k) DO UPDATE SET v = excluded.v, expires_at = excluded.expires_at", table)
+}
+
+func (sqliteDialect) MaxParams() int {
+	// 兼容旧版本SQLite的SQLITE_MAX_VARIABLE_NUMBER默认值
+	return 999
+}
+
+// placeholders 生成从start开始的n个占位符，以逗号分隔
+func placeholders(d Dialect, start, n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = d.Placeholder(start + i)
+	}
+	return strings.Join(parts, ", ")
+}
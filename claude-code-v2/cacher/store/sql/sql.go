@@ -0,0 +1,394 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// tableNamePattern 表名只允许标识符字符，防止拼接SQL时被注入
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Options SQL Store配置
+type Options struct {
+	// Table 缓存表名，默认cache_entries
+	Table string
+
+	// Codec 值编解码器，为nil时使用store.DefaultCodec
+	Codec store.Codec
+
+	// BatchSize IN查询每批的最大键数量，0使用方言允许的最大值
+	BatchSize int
+
+	// SweepInterval 后台清理过期行的间隔，0使用默认值1分钟，负数表示禁用
+	SweepInterval time.Duration
+
+	// DeleteCorrupt 启用后MGet中无法解码的键在返回*store.BatchError的同时被删除
+	DeleteCorrupt bool
+}
+
+// Store 基于database/sql的Store实现
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+	codec   store.Codec
+
+	batchSize     int
+	deleteCorrupt bool
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewStore 创建新的SQL Store实例，并在表不存在时自动建表
+// db: 数据库连接，由调用方负责关闭
+// dialect: 数据库方言，可选Postgres、MySQL、SQLite
+// opts: 配置项，可以为nil使用默认配置
+func NewStore(ctx context.Context, db *sql.DB, dialect Dialect, opts *Options) (*Store, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Table == "" {
+		o.Table = "cache_entries"
+	}
+	if !tableNamePattern.MatchString(o.Table) {
+		return nil, fmt.Errorf("invalid table name %q", o.Table)
+	}
+	if o.Codec == nil {
+		o.Codec = store.DefaultCodec
+	}
+	if o.SweepInterval == 0 {
+		o.SweepInterval = time.Minute
+	}
+
+	// 预留一个参数给过期时间条件
+	maxBatch := dialect.MaxParams() - 1
+	if o.BatchSize <= 0 || o.BatchSize > maxBatch {
+		o.BatchSize = maxBatch
+	}
+
+	for _, stmt := range dialect.CreateTable(o.Table) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("failed to create cache table: %w", err)
+		}
+	}
+
+	s := &Store{
+		db:            db,
+		dialect:       dialect,
+		table:         o.Table,
+		codec:         o.Codec,
+		batchSize:     o.BatchSize,
+		deleteCorrupt: o.DeleteCorrupt,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go s.run(o.SweepInterval)
+
+	return s, nil
+}
+
+// Close 停止后台清理任务，不会关闭数据库连接
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
+
+// Ping 检查数据库连接
+func (s *Store) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("sql ping error: %w", err)
+	}
+	return nil
+}
+
+// Flush 删除缓存表中的所有行
+func (s *Store) Flush(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", s.table)); err != nil {
+		return fmt.Errorf("sql flush error: %w", err)
+	}
+	return nil
+}
+
+// run 后台定期清理过期行
+func (s *Store) run(interval time.Duration) {
+	defer close(s.done)
+
+	if interval < 0 {
+		<-s.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			// 后台任务出错时等待下一轮重试
+			_, _ = s.DeleteExpired(context.Background())
+		}
+	}
+}
+
+// Get 从数据库获取单个值
+func (s *Store) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	query := fmt.Sprintf("SELECT v FROM %s WHERE k = %s AND (expires_at = 0 OR expires_at > %s)",
+		s.table, s.dialect.Placeholder(1), s.dialect.Placeholder(2))
+
+	var payload []byte
+	err := s.db.QueryRowContext(ctx, query, key, time.Now().UnixNano()).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("sql get error: %w", err)
+	}
+
+	if err := s.codec.Unmarshal(payload, dst); err != nil {
+		return false, fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return true, nil
+}
+
+// MGet 使用分批的IN查询批量获取值到map中
+func (s *Store) MGet(ctx context.Context, keys []string, dstMap interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// 验证dstMap是map指针
+	dstMapValue := reflect.ValueOf(dstMap)
+	if dstMapValue.Kind() != reflect.Ptr || dstMapValue.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("dstMap must be a pointer to map")
+	}
+
+	mapValue := dstMapValue.Elem()
+	mapType := mapValue.Type()
+	valueType := mapType.Elem()
+
+	// 如果map为nil，初始化它
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMap(mapType))
+	}
+
+	// 无法解码的键跳过并记录，不影响其他键
+	batchErr := &store.BatchError{}
+	err := s.queryChunks(ctx, keys, "k, v", func(rows *sql.Rows) error {
+		var key string
+		var payload []byte
+		if err := rows.Scan(&key, &payload); err != nil {
+			return err
+		}
+
+		// 创建值类型的新实例并解码
+		valuePtr := reflect.New(valueType)
+		if err := s.codec.Unmarshal(payload, valuePtr.Interface()); err != nil {
+			batchErr.Add(key, fmt.Errorf("failed to decode value: %w", err))
+			return nil
+		}
+
+		mapValue.SetMapIndex(reflect.ValueOf(key), valuePtr.Elem())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.deleteCorrupt && len(batchErr.Errors) > 0 {
+		// 删除失败不影响返回结果，下次读取时会再次尝试
+		_, _ = s.Del(ctx, batchErr.Keys()...)
+	}
+	return batchErr.Err()
+}
+
+// Exists 使用分批的IN查询批量检查键存在性
+func (s *Store) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool)
+
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	for _, key := range keys {
+		result[key] = false
+	}
+
+	err := s.queryChunks(ctx, keys, "k", func(rows *sql.Rows) error {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return err
+		}
+		result[key] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MSet 在单个事务中批量upsert键值对，支持TTL
+func (s *Store) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	// 在事务外完成编码，缩短事务的持有时间
+	payloads := make(map[string][]byte, len(items))
+	for key, value := range items {
+		payload, err := s.codec.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode value for key %s: %w", key, err)
+		}
+		payloads[key] = payload
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sql begin error: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, s.dialect.Upsert(s.table))
+	if err != nil {
+		return fmt.Errorf("sql prepare error: %w", err)
+	}
+	defer stmt.Close()
+
+	for key, payload := range payloads {
+		if _, err := stmt.ExecContext(ctx, key, payload, expiresAt); err != nil {
+			return fmt.Errorf("sql upsert error for key %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sql commit error: %w", err)
+	}
+
+	return nil
+}
+
+// Del 删除指定键，已过期但尚未清理的行不计入删除数量
+func (s *Store) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	var deletedCount int64
+	for _, chunk := range chunkKeys(keys, s.batchSize) {
+		query := fmt.Sprintf("DELETE FROM %s WHERE k IN (%s) AND (expires_at = 0 OR expires_at > %s)",
+			s.table, placeholders(s.dialect, 1, len(chunk)), s.dialect.Placeholder(len(chunk)+1))
+
+		res, err := s.db.ExecContext(ctx, query, chunkArgs(chunk)...)
+		if err != nil {
+			return deletedCount, fmt.Errorf("sql del error: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return deletedCount, fmt.Errorf("sql del error: %w", err)
+		}
+		deletedCount += affected
+	}
+
+	return deletedCount, nil
+}
+
+// DeleteExpired 删除所有已过期的行，返回删除的数量
+// 后台清理任务会定期调用此方法，也可以手动调用
+func (s *Store) DeleteExpired(ctx context.Context) (int, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE expires_at <> 0 AND expires_at <= %s",
+		s.table, s.dialect.Placeholder(1))
+
+	res, err := s.db.ExecContext(ctx, query, time.Now().UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("sql sweep error: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("sql sweep error: %w", err)
+	}
+
+	return int(affected), nil
+}
+
+// queryChunks 按批次执行 SELECT columns ... WHERE k IN (...) 查询，并对每一行调用scan
+func (s *Store) queryChunks(ctx context.Context, keys []string, columns string, scan func(rows *sql.Rows) error) error {
+	for _, chunk := range chunkKeys(keys, s.batchSize) {
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE k IN (%s) AND (expires_at = 0 OR expires_at > %s)",
+			columns, s.table, placeholders(s.dialect, 1, len(chunk)), s.dialect.Placeholder(len(chunk)+1))
+
+		rows, err := s.db.QueryContext(ctx, query, chunkArgs(chunk)...)
+		if err != nil {
+			return fmt.Errorf("sql query error: %w", err)
+		}
+
+		for rows.Next() {
+			if err := scan(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("sql query error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// chunkKeys 将键列表按size切分
+func chunkKeys(keys []string, size int) [][]string {
+	chunks := make([][]string, 0, (len(keys)+size-1)/size)
+	for start := 0; start < len(keys); start += size {
+		end := start + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		chunks = append(chunks, keys[start:end])
+	}
+	return chunks
+}
+
+// chunkArgs 生成IN查询的参数，最后一个参数为当前时间
+func chunkArgs(chunk []string) []interface{} {
+	args := make([]interface{}, 0, len(chunk)+1)
+	for _, key := range chunk {
+		args = append(args, key)
+	}
+	return append(args, time.Now().UnixNano())
+}
+
+// 确保Store实现了store.Store、store.Closer、store.Pinger和store.Flusher接口
+var (
+	_ store.Store   = (*Store)(nil)
+	_ store.Closer  = (*Store)(nil)
+	_ store.Pinger  = (*Store)(nil)
+	_ store.Flusher = (*Store)(nil)
+)
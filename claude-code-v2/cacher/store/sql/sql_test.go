@@ -0,0 +1,101 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+	_ "modernc.org/sqlite"
+)
+
+// openSQLite 在临时目录中打开SQLite数据库
+func openSQLite(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLStore(t *testing.T) {
+	// 创建SQL Store
+	sqlStore, err := NewStore(context.Background(), openSQLite(t), SQLite, nil)
+	require.NoError(t, err)
+	defer sqlStore.Close()
+
+	// 运行通用测试套件
+	testHelper := store.NewTestHelper(t, sqlStore)
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+
+	assert.NoError(t, sqlStore.Ping(context.Background()))
+}
+
+func TestSQLStoreChunkedQueries(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := NewStore(ctx, openSQLite(t), SQLite, &Options{Table: "chunked", BatchSize: 3})
+	require.NoError(t, err)
+	defer sqlStore.Close()
+
+	items := make(map[string]interface{})
+	keys := make([]string, 0, 10)
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("chunk_key_%d", i)
+		items[key] = i
+		keys = append(keys, key)
+	}
+	require.NoError(t, sqlStore.MSet(ctx, items, 0))
+
+	// 键数量超过BatchSize时分批查询并合并结果
+	resultMap := make(map[string]int)
+	require.NoError(t, sqlStore.MGet(ctx, append(keys, "missing"), &resultMap))
+	assert.Len(t, resultMap, 10)
+	assert.Equal(t, 7, resultMap["chunk_key_7"])
+
+	exists, err := sqlStore.Exists(ctx, append(keys, "missing"))
+	require.NoError(t, err)
+	assert.Len(t, exists, 11)
+	assert.False(t, exists["missing"])
+
+	deleted, err := sqlStore.Del(ctx, keys...)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), deleted)
+}
+
+func TestSQLStoreDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	sqlStore, err := NewStore(ctx, openSQLite(t), SQLite, &Options{SweepInterval: -1})
+	require.NoError(t, err)
+	defer sqlStore.Close()
+
+	require.NoError(t, sqlStore.MSet(ctx, map[string]interface{}{"short": 1}, 50*time.Millisecond))
+	require.NoError(t, sqlStore.MSet(ctx, map[string]interface{}{"forever": 2}, 0))
+
+	time.Sleep(100 * time.Millisecond)
+
+	deleted, err := sqlStore.DeleteExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	exists, err := sqlStore.Exists(ctx, []string{"short", "forever"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"short": false, "forever": true}, exists)
+}
+
+func TestSQLStoreInvalidTable(t *testing.T) {
+	_, err := NewStore(context.Background(), openSQLite(t), SQLite, &Options{Table: "cache; DROP TABLE x"})
+	assert.Error(t, err)
+}
+
+func TestDialectStatements(t *testing.T) {
+	assert.Equal(t, "$1, $2, $3", placeholders(Postgres, 1, 3))
+	assert.Equal(t, "?, ?", placeholders(MySQL, 1, 2))
+	assert.Contains(t, Postgres.Upsert("cache"), "ON CONFLICT (k) DO UPDATE")
+	assert.Contains(t, MySQL.Upsert("cache"), "ON DUPLICATE KEY UPDATE")
+	assert.Contains(t, SQLite.Upsert("cache"), "ON CONFLICT (k) DO UPDATE")
+}
@@ -16,7 +16,7 @@ type Store interface {
 	// MGet 批量获取值到map中
 	// keys: 要获取的键列表
 	// dstMap: 目标map的指针，用于接收结果，类型为*map[string]T
-	// 返回: 错误信息
+	// 返回: 错误信息，部分键无法解码时跳过这些键(其余键仍写入dstMap)并返回*BatchError
 	MGet(ctx context.Context, keys []string, dstMap interface{}) error
 
 	// Exists 批量检查键存在性
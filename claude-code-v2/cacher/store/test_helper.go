@@ -70,7 +70,7 @@ func (th *TestHelper) TestGet() {
 		Name string `json:"name"`
 		Age  int    `json:"age"`
 	}
-	
+
 	original := TestStruct{Name: "Alice", Age: 30}
 	err = th.Store.MSet(ctx, map[string]interface{}{"struct_key": original}, 0)
 	require.NoError(t, err)
@@ -122,6 +122,16 @@ func (th *TestHelper) TestMGet() {
 	err = th.Store.MGet(ctx, []string{}, &emptyResultMap)
 	assert.NoError(t, err)
 	assert.Len(t, emptyResultMap, 0)
+
+	// 测试部分键无法解码：跳过这些键，其余键仍然返回
+	err = th.Store.MSet(ctx, map[string]interface{}{"mget_int": 1, "mget_bad": "not a number"}, 0)
+	require.NoError(t, err)
+	intResultMap := make(map[string]int)
+	err = th.Store.MGet(ctx, []string{"mget_int", "mget_bad", "nonexistent"}, &intResultMap)
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []string{"mget_bad"}, batchErr.Keys())
+	assert.Equal(t, map[string]int{"mget_int": 1}, intResultMap)
 }
 
 // TestExists 测试Exists方法
@@ -319,4 +329,409 @@ func (th *TestHelper) TestEdgeCases() {
 	assert.NoError(t, err)
 	assert.True(t, found)
 	assert.Equal(t, 0, intResult)
-}
\ No newline at end of file
+}
+
+// TestExpirer 测试Expirer接口，Store需要实现Expirer，由各实现的测试显式调用
+func (th *TestHelper) TestExpirer() {
+	ctx := context.Background()
+	t := th.t
+
+	expirer, ok := th.Store.(Expirer)
+	require.True(t, ok, "store does not implement Expirer")
+
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"expirer_ttl": "v"}, time.Hour))
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"expirer_forever": "v"}, 0))
+
+	// 读取剩余TTL，不存在的键不包含在结果中
+	ttls, err := expirer.TTL(ctx, []string{"expirer_ttl", "expirer_forever", "expirer_missing"})
+	require.NoError(t, err)
+	assert.Len(t, ttls, 2)
+	assert.InDelta(t, float64(time.Hour), float64(ttls["expirer_ttl"]), float64(time.Minute))
+	assert.Equal(t, NoTTL, ttls["expirer_forever"])
+
+	// 修改TTL不改变值
+	count, err := expirer.Expire(ctx, []string{"expirer_ttl", "expirer_forever", "expirer_missing"}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	ttls, err = expirer.TTL(ctx, []string{"expirer_ttl", "expirer_forever"})
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Minute), float64(ttls["expirer_ttl"]), float64(time.Second))
+	assert.InDelta(t, float64(time.Minute), float64(ttls["expirer_forever"]), float64(time.Second))
+
+	var result string
+	found, err := th.Store.Get(ctx, "expirer_forever", &result)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v", result)
+
+	// 非正数TTL返回错误
+	_, err = expirer.Expire(ctx, []string{"expirer_ttl"}, 0)
+	assert.Error(t, err)
+
+	// 移除TTL
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"expirer_persistent": "v"}, 0))
+	count, err = expirer.Persist(ctx, []string{"expirer_ttl", "expirer_persistent", "expirer_missing"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	ttls, err = expirer.TTL(ctx, []string{"expirer_ttl"})
+	require.NoError(t, err)
+	assert.Equal(t, NoTTL, ttls["expirer_ttl"])
+}
+
+// TestCounter 测试Counter接口，Store需要实现Counter，由各实现的测试显式调用
+func (th *TestHelper) TestCounter() {
+	ctx := context.Background()
+	t := th.t
+
+	counter, ok := th.Store.(Counter)
+	require.True(t, ok, "store does not implement Counter")
+
+	// 不存在的计数器从0开始
+	value, err := counter.IncrBy(ctx, "counter_new", 5, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	value, err = counter.IncrBy(ctx, "counter_new", -7, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2), value)
+
+	// 计数器可以通过Get读取
+	var result int64
+	found, err := th.Store.Get(ctx, "counter_new", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(-2), result)
+
+	// 已通过MSet写入的整数可以继续累加
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"counter_set": 10}, 0))
+	value, err = counter.IncrBy(ctx, "counter_set", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), value)
+
+	// 非整数值返回错误
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"counter_string": "abc"}, 0))
+	_, err = counter.IncrBy(ctx, "counter_string", 1, 0)
+	assert.Error(t, err)
+
+	// 批量增加
+	values, err := counter.MIncrBy(ctx, map[string]int64{"counter_new": 2, "counter_batch": 3}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"counter_new": 0, "counter_batch": 3}, values)
+
+	// TTL只在计数器没有过期时间时设置
+	expirer, ok := th.Store.(Expirer)
+	if !ok {
+		return
+	}
+	_, err = counter.IncrBy(ctx, "counter_ttl", 1, time.Hour)
+	require.NoError(t, err)
+	_, err = counter.IncrBy(ctx, "counter_ttl", 1, time.Minute)
+	require.NoError(t, err)
+	_, err = counter.IncrBy(ctx, "counter_new", 1, time.Minute)
+	require.NoError(t, err)
+
+	ttls, err := expirer.TTL(ctx, []string{"counter_ttl", "counter_new"})
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Hour), float64(ttls["counter_ttl"]), float64(time.Minute))
+	assert.InDelta(t, float64(time.Minute), float64(ttls["counter_new"]), float64(time.Second))
+}
+
+// TestCAS 测试CAS接口，Store需要实现CAS，由各实现的测试显式调用
+func (th *TestHelper) TestCAS() {
+	ctx := context.Background()
+	t := th.t
+
+	cas, ok := th.Store.(CAS)
+	require.True(t, ok, "store does not implement CAS")
+
+	// 不存在的键返回NoVersion，只能以NoVersion写入
+	var result string
+	version, found, err := cas.GetVersioned(ctx, "cas_key", &result)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, NoVersion, version)
+
+	set, err := cas.SetIfVersion(ctx, "cas_key", "v1", NoVersion, 0)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	set, err = cas.SetIfVersion(ctx, "cas_key", "other", NoVersion, 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	// 版本匹配时写入，旧版本写入失败
+	version, found, err = cas.GetVersioned(ctx, "cas_key", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v1", result)
+	assert.NotEqual(t, NoVersion, version)
+
+	set, err = cas.SetIfVersion(ctx, "cas_key", "v2", version, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, set)
+
+	set, err = cas.SetIfVersion(ctx, "cas_key", "v3", version, 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	found, err = th.Store.Get(ctx, "cas_key", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "v2", result)
+
+	// 其他写入改变版本
+	version, _, err = cas.GetVersioned(ctx, "cas_key", &result)
+	require.NoError(t, err)
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"cas_key": "v4"}, 0))
+	set, err = cas.SetIfVersion(ctx, "cas_key", "v5", version, 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	// 删除后旧版本失效
+	version, _, err = cas.GetVersioned(ctx, "cas_key", &result)
+	require.NoError(t, err)
+	_, err = th.Store.Del(ctx, "cas_key")
+	require.NoError(t, err)
+	set, err = cas.SetIfVersion(ctx, "cas_key", "v6", version, 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+}
+
+// TestConditionalSetter 测试ConditionalSetter接口，Store需要实现ConditionalSetter，由各实现的测试显式调用
+func (th *TestHelper) TestConditionalSetter() {
+	ctx := context.Background()
+	t := th.t
+
+	setter, ok := th.Store.(ConditionalSetter)
+	require.True(t, ok, "store does not implement ConditionalSetter")
+
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"cond_existing": "old"}, 0))
+
+	// MSetNX只写入不存在的键
+	written, err := setter.MSetNX(ctx, map[string]interface{}{
+		"cond_existing": "new",
+		"cond_missing":  "new",
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"cond_existing": false, "cond_missing": true}, written)
+
+	var result string
+	_, err = th.Store.Get(ctx, "cond_existing", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "old", result)
+	_, err = th.Store.Get(ctx, "cond_missing", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "new", result)
+
+	// MSetXX只写入已存在的键
+	written, err = setter.MSetXX(ctx, map[string]interface{}{
+		"cond_existing": "replaced",
+		"cond_absent":   "replaced",
+	}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"cond_existing": true, "cond_absent": false}, written)
+
+	_, err = th.Store.Get(ctx, "cond_existing", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "replaced", result)
+
+	exists, err := th.Store.Exists(ctx, []string{"cond_absent"})
+	require.NoError(t, err)
+	assert.False(t, exists["cond_absent"])
+
+	// MSetEntriesNX只写入不存在的键，每个键使用各自的过期时间
+	written, err = setter.MSetEntriesNX(ctx, []Entry{
+		{Key: "cond_existing", Value: "entry", TTL: time.Minute},
+		{Key: "cond_entry_short", Value: "entry", TTL: time.Minute},
+		{Key: "cond_entry_long", Value: "entry", TTL: time.Hour},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"cond_existing": false, "cond_entry_short": true, "cond_entry_long": true}, written)
+
+	_, err = th.Store.Get(ctx, "cond_existing", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "replaced", result)
+	if expirer, ok := th.Store.(Expirer); ok {
+		ttls, err := expirer.TTL(ctx, []string{"cond_entry_short", "cond_entry_long"})
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttls["cond_entry_short"], float64(time.Second))
+		assert.InDelta(t, time.Hour, ttls["cond_entry_long"], float64(time.Second))
+	}
+
+	// 空输入
+	written, err = setter.MSetNX(ctx, map[string]interface{}{}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, written)
+}
+
+// TestFlusher 测试Flusher接口，Store需要实现Flusher，由各实现的测试显式调用
+func (th *TestHelper) TestFlusher() {
+	ctx := context.Background()
+	t := th.t
+
+	flusher, ok := th.Store.(Flusher)
+	require.True(t, ok, "store does not implement Flusher")
+
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"flush_a": "a", "flush_b": "b"}, 0))
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"flush_c": "c"}, time.Hour))
+
+	require.NoError(t, flusher.Flush(ctx))
+
+	exists, err := th.Store.Exists(ctx, []string{"flush_a", "flush_b", "flush_c"})
+	require.NoError(t, err)
+	assert.False(t, exists["flush_a"])
+	assert.False(t, exists["flush_b"])
+	assert.False(t, exists["flush_c"])
+
+	// 清空后可以继续写入
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"flush_a": "a2"}, 0))
+	var result string
+	found, err := th.Store.Get(ctx, "flush_a", &result)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a2", result)
+}
+
+// TestEntrySetter 测试EntrySetter接口，Store需要实现EntrySetter，由各实现的测试显式调用
+// Store同时实现Expirer时检查每个键的过期时间
+func (th *TestHelper) TestEntrySetter() {
+	ctx := context.Background()
+	t := th.t
+
+	setter, ok := th.Store.(EntrySetter)
+	require.True(t, ok, "store does not implement EntrySetter")
+
+	err := setter.MSetEntries(ctx, []Entry{
+		{Key: "entry_short", Value: "short", TTL: time.Minute},
+		{Key: "entry_long", Value: "long", TTL: time.Hour},
+		{Key: "entry_forever", Value: "forever"},
+	})
+	require.NoError(t, err)
+
+	var result string
+	for key, expected := range map[string]string{
+		"entry_short":   "short",
+		"entry_long":    "long",
+		"entry_forever": "forever",
+	} {
+		found, err := th.Store.Get(ctx, key, &result)
+		require.NoError(t, err)
+		assert.True(t, found, key)
+		assert.Equal(t, expected, result)
+	}
+
+	if expirer, ok := th.Store.(Expirer); ok {
+		ttls, err := expirer.TTL(ctx, []string{"entry_short", "entry_long", "entry_forever"})
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, ttls["entry_short"], float64(5*time.Second))
+		assert.InDelta(t, time.Hour, ttls["entry_long"], float64(5*time.Second))
+		assert.Equal(t, NoTTL, ttls["entry_forever"])
+	}
+
+	// 同一个键出现多次时以最后一个为准
+	err = setter.MSetEntries(ctx, []Entry{
+		{Key: "entry_short", Value: "first"},
+		{Key: "entry_short", Value: "last"},
+	})
+	require.NoError(t, err)
+	_, err = th.Store.Get(ctx, "entry_short", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "last", result)
+
+	// 空输入
+	require.NoError(t, setter.MSetEntries(ctx, nil))
+}
+
+// TestEvictNotifier 测试EvictNotifier接口，Store需要实现EvictNotifier，由各实现的测试显式调用
+// 只检查所有实现都能报告的删除事件，事件可能异步到达
+func (th *TestHelper) TestEvictNotifier() {
+	ctx := context.Background()
+	t := th.t
+
+	notifier, ok := th.Store.(EvictNotifier)
+	require.True(t, ok, "store does not implement EvictNotifier")
+
+	// 回调在测试结束后仍然注册在Store上，channel已满时丢弃事件避免阻塞
+	deleted := make(chan string, 16)
+	notifier.OnEvict(func(event Event) {
+		if event.Reason != EvictDeleted {
+			return
+		}
+		select {
+		case deleted <- event.Key:
+		default:
+		}
+	})
+
+	err := th.Store.MSet(ctx, map[string]interface{}{"evict_a": "a", "evict_b": "b"}, 0)
+	require.NoError(t, err)
+
+	// 删除不存在的键不产生事件
+	count, err := th.Store.Del(ctx, "evict_a", "evict_missing")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	select {
+	case key := <-deleted:
+		assert.Equal(t, "evict_a", key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("deleted event not received")
+	}
+
+	select {
+	case key := <-deleted:
+		t.Fatalf("unexpected deleted event for %s", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = th.Store.Del(ctx, "evict_b")
+	require.NoError(t, err)
+}
+
+// TestWatcher 测试Watcher接口，Store需要实现Watcher，由各实现的测试显式调用
+// 只检查所有实现都能报告的写入和删除事件，事件可能异步到达
+func (th *TestHelper) TestWatcher() {
+	t := th.t
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, ok := th.Store.(Watcher)
+	require.True(t, ok, "store does not implement Watcher")
+
+	events, err := watcher.Watch(ctx, "watch_*")
+	require.NoError(t, err)
+
+	require.NoError(t, th.Store.MSet(ctx, map[string]interface{}{"watch_a": "a", "other": "b"}, 0))
+	_, err = th.Store.Del(ctx, "watch_a", "other", "watch_missing")
+	require.NoError(t, err)
+
+	// 不匹配的键和不存在的键不产生事件
+	for _, want := range []WatchEvent{
+		{Key: "watch_a", Type: WatchSet},
+		{Key: "watch_a", Type: WatchDelete},
+	} {
+		select {
+		case event := <-events:
+			assert.Equal(t, want, event)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s event for %s not received", want.Type, want.Key)
+		}
+	}
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 取消后channel关闭
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch channel not closed")
+	}
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultWatchBuffer WatchHub每个订阅者默认的缓冲区大小
+const DefaultWatchBuffer = 64
+
+// WatchEventType 键变化的类型
+type WatchEventType int
+
+const (
+	// WatchSet 键被写入，包括新建和覆盖
+	WatchSet WatchEventType = iota + 1
+	// WatchDelete 键被删除或因容量不足被淘汰
+	WatchDelete
+	// WatchExpire 键过期
+	WatchExpire
+)
+
+// String 返回类型的名称
+func (t WatchEventType) String() string {
+	switch t {
+	case WatchSet:
+		return "set"
+	case WatchDelete:
+		return "delete"
+	case WatchExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// WatchEvent 键变化的事件
+type WatchEvent struct {
+	Key  string
+	Type WatchEventType
+	// Missed 订阅者处理过慢，在本事件之前被丢弃的事件数量，不为0时应重新读取关心的键
+	Missed uint64
+}
+
+// WatchHub 进程内的Watch扇出，供Store实现Watcher
+// 每个订阅者有独立的有界缓冲区，缓冲区已满时丢弃事件并计数，不会阻塞写入方
+type WatchHub struct {
+	// BufferSize 每个订阅者的缓冲区大小，0使用DefaultWatchBuffer
+	BufferSize int
+
+	mutex   sync.RWMutex
+	streams map[*WatchStream]struct{}
+
+	dropped atomic.Uint64
+}
+
+// WatchStream 单个订阅者
+type WatchStream struct {
+	hub     *WatchHub
+	pattern string
+
+	// mutex 保护channel的发送与关闭
+	mutex  sync.Mutex
+	ch     chan WatchEvent
+	closed bool
+	missed uint64
+
+	dropped atomic.Uint64
+}
+
+// Add 添加订阅匹配pattern的键的订阅者，ctx被取消时移除订阅者并关闭其channel
+// pattern: Redis风格的glob模式，空字符串匹配所有键
+func (h *WatchHub) Add(ctx context.Context, pattern string) *WatchStream {
+	size := h.BufferSize
+	if size <= 0 {
+		size = DefaultWatchBuffer
+	}
+
+	stream := &WatchStream{
+		hub:     h,
+		pattern: pattern,
+		ch:      make(chan WatchEvent, size),
+	}
+
+	h.mutex.Lock()
+	if h.streams == nil {
+		h.streams = make(map[*WatchStream]struct{})
+	}
+	h.streams[stream] = struct{}{}
+	h.mutex.Unlock()
+
+	context.AfterFunc(ctx, stream.close)
+	return stream
+}
+
+// Active 判断是否有订阅者，没有订阅者时Store可以跳过事件相关的额外开销
+func (h *WatchHub) Active() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.streams) > 0
+}
+
+// Publish 将事件发送给所有模式匹配的订阅者
+func (h *WatchHub) Publish(event WatchEvent) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for stream := range h.streams {
+		if MatchPattern(stream.pattern, event.Key) {
+			stream.Send(event)
+		}
+	}
+}
+
+// Dropped 返回所有订阅者累计丢弃的事件数量
+func (h *WatchHub) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Events 返回接收事件的channel，订阅结束时关闭
+func (s *WatchStream) Events() <-chan WatchEvent {
+	return s.ch
+}
+
+// Send 不阻塞地发送事件，缓冲区已满或订阅已结束时丢弃
+// 返回: 是否发送成功
+func (s *WatchStream) Send(event WatchEvent) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	event.Missed = s.missed
+	select {
+	case s.ch <- event:
+		s.missed = 0
+		return true
+	default:
+		s.missed++
+		s.dropped.Add(1)
+		s.hub.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped 返回该订阅者累计丢弃的事件数量
+func (s *WatchStream) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// close 移除订阅者并关闭channel
+func (s *WatchStream) close() {
+	s.hub.mutex.Lock()
+	delete(s.hub.streams, s)
+	s.hub.mutex.Unlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
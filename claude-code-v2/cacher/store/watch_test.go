@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchHub(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := &WatchHub{BufferSize: 2}
+	assert.False(t, hub.Active())
+
+	users := hub.Add(ctx, "user:*")
+	all := hub.Add(ctx, "")
+	assert.True(t, hub.Active())
+
+	hub.Publish(WatchEvent{Key: "user:1", Type: WatchSet})
+	hub.Publish(WatchEvent{Key: "order:1", Type: WatchDelete})
+
+	assert.Equal(t, WatchEvent{Key: "user:1", Type: WatchSet}, <-users.Events())
+	assert.Equal(t, WatchEvent{Key: "user:1", Type: WatchSet}, <-all.Events())
+	assert.Equal(t, WatchEvent{Key: "order:1", Type: WatchDelete}, <-all.Events())
+
+	// 缓冲区已满时丢弃事件，下一个送达的事件携带丢弃的数量
+	for i := 0; i < 5; i++ {
+		hub.Publish(WatchEvent{Key: "user:2", Type: WatchSet})
+	}
+	assert.Equal(t, uint64(3), users.Dropped())
+	assert.Equal(t, uint64(6), hub.Dropped())
+
+	<-users.Events()
+	<-users.Events()
+	hub.Publish(WatchEvent{Key: "user:3", Type: WatchExpire})
+	assert.Equal(t, WatchEvent{Key: "user:3", Type: WatchExpire, Missed: 3}, <-users.Events())
+
+	hub.Publish(WatchEvent{Key: "user:4", Type: WatchExpire})
+	assert.Equal(t, uint64(0), (<-users.Events()).Missed)
+
+	// 取消后channel关闭
+	cancel()
+	for range all.Events() {
+	}
+	_, ok := <-users.Events()
+	require.False(t, ok)
+	assert.False(t, hub.Active())
+	assert.False(t, users.Send(WatchEvent{Key: "user:5", Type: WatchSet}))
+}
+
+func TestWatchEventTypeString(t *testing.T) {
+	assert.Equal(t, "set", WatchSet.String())
+	assert.Equal(t, "delete", WatchDelete.String())
+	assert.Equal(t, "expire", WatchExpire.String())
+	assert.Equal(t, "unknown", WatchEventType(0).String())
+}
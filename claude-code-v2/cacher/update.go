@@ -0,0 +1,61 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// maxUpdateAttempts Update因版本冲突重试的最大次数
+const maxUpdateAttempts = 10
+
+// Update 读取键的当前值和版本，执行fn后仅在版本未变时写回，冲突时重新读取并重试
+func (c *CacherImpl) Update(ctx context.Context, key string, dst interface{}, fn UpdateFunc, opts *CacheOptions) error {
+	cas, ok := store.As[store.CAS](c.store)
+	if !ok {
+		return &NotSupportedError{Operation: "Update"}
+	}
+
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() {
+		return fmt.Errorf("dst must be a non-nil pointer")
+	}
+	dstElem := dstValue.Elem()
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		// 每次重试前清空dst，避免上一轮的修改残留
+		dstElem.SetZero()
+
+		version, found, err := cas.GetVersioned(ctx, key, dst)
+		if err != nil {
+			return fmt.Errorf("failed to get from store: %w", err)
+		}
+
+		if err := fn(ctx, found); err != nil {
+			return err
+		}
+
+		set, err := cas.SetIfVersion(ctx, key, dstElem.Interface(), version, c.writeTTL(opts))
+		if err != nil {
+			return fmt.Errorf("failed to set to store: %w", err)
+		}
+		if set {
+			c.written([]string{key}, opts)
+			return nil
+		}
+
+		// 随机退避后重试，减少多个写入方再次同时冲突
+		backoff := time.Duration(rand.Int64N(int64(attempt+1) * int64(time.Millisecond)))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return ErrConflict
+}
@@ -0,0 +1,388 @@
+package cacher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// KeySource 预热键的来源
+type KeySource interface {
+	// Keys 依次产生待预热的键，yield返回false时应停止产生并返回nil
+	// 返回: 读取键时的错误
+	Keys(ctx context.Context, yield func(key string) bool) error
+}
+
+// KeySourceFunc 函数形式的KeySource
+type KeySourceFunc func(ctx context.Context, yield func(key string) bool) error
+
+// Keys 实现KeySource接口
+func (f KeySourceFunc) Keys(ctx context.Context, yield func(key string) bool) error {
+	return f(ctx, yield)
+}
+
+// SliceKeys 从切片读取键
+func SliceKeys(keys []string) KeySource {
+	return SeqKeys(func(yield func(string) bool) {
+		for _, key := range keys {
+			if !yield(key) {
+				return
+			}
+		}
+	})
+}
+
+// SeqKeys 从迭代器读取键
+func SeqKeys(seq iter.Seq[string]) KeySource {
+	return KeySourceFunc(func(ctx context.Context, yield func(string) bool) error {
+		for key := range seq {
+			if !yield(key) {
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// ChanKeys 从channel读取键，直到channel关闭或ctx取消
+func ChanKeys(ch <-chan string) KeySource {
+	return KeySourceFunc(func(ctx context.Context, yield func(string) bool) error {
+		for {
+			select {
+			case key, ok := <-ch:
+				if !ok || !yield(key) {
+					return nil
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+}
+
+// ReaderKeys 从r按行读取键，忽略首尾空白、空行和以#开头的注释行
+func ReaderKeys(r io.Reader) KeySource {
+	return KeySourceFunc(func(ctx context.Context, yield func(string) bool) error {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			key := strings.TrimSpace(scanner.Text())
+			if key == "" || strings.HasPrefix(key, "#") {
+				continue
+			}
+			if !yield(key) {
+				return nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read keys: %w", err)
+		}
+		return nil
+	})
+}
+
+// FileKeys 从文件按行读取键，格式同ReaderKeys
+func FileKeys(path string) KeySource {
+	return KeySourceFunc(func(ctx context.Context, yield func(string) bool) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open key file: %w", err)
+		}
+		defer f.Close()
+
+		return ReaderKeys(f).Keys(ctx, yield)
+	})
+}
+
+// WarmProgress 预热进度
+type WarmProgress struct {
+	// Keys 已处理的键数量
+	Keys int64
+	// Loaded 回退函数返回并写入缓存的键数量，包括回退函数额外返回的未请求的键
+	Loaded int64
+	// Missing 请求的键中回退函数未返回的数量
+	Missing int64
+	// Skipped 已在缓存中而跳过的键数量(仅SkipCached时)
+	Skipped int64
+	// Failed 因回退函数或写入缓存出错而失败的键数量
+	Failed int64
+	// Elapsed 已用时间
+	Elapsed time.Duration
+}
+
+// WarmerOptions 预热配置
+type WarmerOptions struct {
+	// TTL 写入缓存的过期时间，0表示永不过期
+	TTL time.Duration
+
+	// TTLJitter TTL的随机增量上限，每批在[0, TTLJitter)内随机增加，避免预热的键同时过期
+	TTLJitter time.Duration
+
+	// ChunkSize 每次调用回退函数的键数量，0使用默认值100
+	ChunkSize int
+
+	// Parallelism 并发调用回退函数的数量，0使用默认值4
+	Parallelism int
+
+	// Rate 每秒最多预热的键数量，0表示不限制
+	Rate float64
+
+	// SkipCached 为true时先用Exists过滤已在缓存中的键
+	SkipCached bool
+
+	// OnProgress 每批完成后回调，调用是串行的
+	OnProgress func(progress WarmProgress)
+
+	// OnError 某批失败时回调，该批的键计入Failed，预热继续
+	OnError func(keys []string, err error)
+}
+
+// Warmer 缓存预热器，从KeySource读取键，通过批量回退函数加载数据并写入Store
+// 适合在启动或Redis故障切换后使用，以受控的速率和并发重建缓存，避免回源流量突增
+type Warmer struct {
+	store    store.Store
+	fallback BatchFallbackFunc
+	opts     WarmerOptions
+}
+
+// NewWarmer 创建新的预热器
+// opts: 配置项，可以为nil使用默认配置
+func NewWarmer(s store.Store, fallback BatchFallbackFunc, opts *WarmerOptions) *Warmer {
+	var o WarmerOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = 100
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = 4
+	}
+
+	return &Warmer{
+		store:    s,
+		fallback: fallback,
+		opts:     o,
+	}
+}
+
+// Warm 预热source中的所有键，直到source读完或ctx取消
+// 单批的错误通过OnError回调报告，不会中止预热
+// 返回: 最终进度, 读取键的错误或ctx的错误
+func (w *Warmer) Warm(ctx context.Context, source KeySource) (WarmProgress, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	run := &warmRun{
+		warmer:  w,
+		start:   time.Now(),
+		limiter: newRateLimiter(w.opts.Rate),
+	}
+
+	chunks := make(chan []string)
+	var wg sync.WaitGroup
+	for i := 0; i < w.opts.Parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				run.process(ctx, chunk)
+			}
+		}()
+	}
+
+	// 读取键并按ChunkSize分批
+	chunk := make([]string, 0, w.opts.ChunkSize)
+	send := func() bool {
+		select {
+		case chunks <- chunk:
+			chunk = make([]string, 0, w.opts.ChunkSize)
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	sourceErr := source.Keys(ctx, func(key string) bool {
+		chunk = append(chunk, key)
+		if len(chunk) < w.opts.ChunkSize {
+			return ctx.Err() == nil
+		}
+		return send()
+	})
+	if sourceErr == nil && len(chunk) > 0 {
+		send()
+	}
+
+	close(chunks)
+	wg.Wait()
+
+	progress := run.snapshot()
+	if sourceErr != nil {
+		return progress, sourceErr
+	}
+	return progress, ctx.Err()
+}
+
+// warmRun 单次预热的状态
+type warmRun struct {
+	warmer  *Warmer
+	start   time.Time
+	limiter *rateLimiter
+
+	keys    atomic.Int64
+	loaded  atomic.Int64
+	missing atomic.Int64
+	skipped atomic.Int64
+	failed  atomic.Int64
+
+	// callbackMutex 保证OnProgress和OnError串行调用
+	callbackMutex sync.Mutex
+}
+
+// process 预热一批键
+func (r *warmRun) process(ctx context.Context, keys []string) {
+	w := r.warmer
+	if ctx.Err() != nil {
+		return
+	}
+
+	err := r.load(ctx, keys)
+	r.keys.Add(int64(len(keys)))
+	if err != nil {
+		r.failed.Add(int64(len(keys)))
+	}
+
+	r.callbackMutex.Lock()
+	defer r.callbackMutex.Unlock()
+	if err != nil && w.opts.OnError != nil {
+		w.opts.OnError(keys, err)
+	}
+	if w.opts.OnProgress != nil {
+		w.opts.OnProgress(r.snapshot())
+	}
+}
+
+// load 过滤已缓存的键，调用回退函数并写入缓存
+func (r *warmRun) load(ctx context.Context, keys []string) error {
+	w := r.warmer
+
+	if w.opts.SkipCached {
+		exists, err := w.store.Exists(ctx, keys)
+		if err != nil {
+			return fmt.Errorf("failed to check existence: %w", err)
+		}
+		missed := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if !exists[key] {
+				missed = append(missed, key)
+			}
+		}
+		r.skipped.Add(int64(len(keys) - len(missed)))
+		keys = missed
+		if len(keys) == 0 {
+			return nil
+		}
+	}
+
+	if err := r.limiter.wait(ctx, len(keys)); err != nil {
+		return err
+	}
+
+	values, err := w.fallback(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("batch fallback error: %w", err)
+	}
+
+	if len(values) > 0 {
+		if err := w.store.MSet(ctx, values, r.ttl()); err != nil {
+			return fmt.Errorf("failed to cache warmed values: %w", err)
+		}
+	}
+
+	// 回退函数可能返回未请求的键，只统计请求的键中未返回的数量
+	found := 0
+	for _, key := range keys {
+		if _, ok := values[key]; ok {
+			found++
+		}
+	}
+	r.loaded.Add(int64(len(values)))
+	r.missing.Add(int64(len(keys) - found))
+	return nil
+}
+
+// ttl 返回加上随机增量后的TTL
+func (r *warmRun) ttl() time.Duration {
+	w := r.warmer
+	if w.opts.TTL <= 0 || w.opts.TTLJitter <= 0 {
+		return w.opts.TTL
+	}
+	return w.opts.TTL + rand.N(w.opts.TTLJitter)
+}
+
+// snapshot 返回当前进度
+func (r *warmRun) snapshot() WarmProgress {
+	return WarmProgress{
+		Keys:    r.keys.Load(),
+		Loaded:  r.loaded.Load(),
+		Missing: r.missing.Load(),
+		Skipped: r.skipped.Load(),
+		Failed:  r.failed.Load(),
+		Elapsed: time.Since(r.start),
+	}
+}
+
+// rateLimiter 按固定速率放行的限速器，n个键需要等待n/rate秒
+type rateLimiter struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	next  time.Time
+}
+
+// newRateLimiter 创建每秒放行rate个键的限速器，rate<=0时不限速
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// wait 等待放行n个键
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	at := l.next
+	l.next = l.next.Add(time.Duration(n) * l.interval)
+	l.mutex.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
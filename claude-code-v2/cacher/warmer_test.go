@@ -0,0 +1,222 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncStore 并发安全的MockStore，记录每次MSet使用的TTL
+type syncStore struct {
+	mutex sync.Mutex
+	*MockStore
+	ttls []time.Duration
+}
+
+func newSyncStore() *syncStore {
+	return &syncStore{MockStore: NewMockStore()}
+}
+
+func (s *syncStore) Exists(ctx context.Context, keys []string) (map[string]bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.MockStore.Exists(ctx, keys)
+}
+
+func (s *syncStore) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ttls = append(s.ttls, ttl)
+	return s.MockStore.MSet(ctx, items, ttl)
+}
+
+// echoFallback 为每个键返回"value_"+key，跳过以missing开头的键
+func echoFallback(calls *atomic.Int64) BatchFallbackFunc {
+	return func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		calls.Add(1)
+		result := make(map[string]interface{})
+		for _, key := range keys {
+			if !strings.HasPrefix(key, "missing") {
+				result[key] = "value_" + key
+			}
+		}
+		return result, nil
+	}
+}
+
+func TestWarmerSources(t *testing.T) {
+	ctx := context.Background()
+	keys := []string{"a", "b", "c", "missing1", "d"}
+
+	path := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# 注释\na\n  b  \n\nc\nmissing1\nd\n"), 0644))
+
+	ch := make(chan string, len(keys))
+	for _, key := range keys {
+		ch <- key
+	}
+	close(ch)
+
+	sources := map[string]KeySource{
+		"slice": SliceKeys(keys),
+		"chan":  ChanKeys(ch),
+		"file":  FileKeys(path),
+		"seq":   SeqKeys(slices.Values(keys)),
+	}
+
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			s := newSyncStore()
+			var calls atomic.Int64
+			warmer := NewWarmer(s, echoFallback(&calls), &WarmerOptions{ChunkSize: 2, Parallelism: 2})
+
+			progress, err := warmer.Warm(ctx, source)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), progress.Keys)
+			assert.Equal(t, int64(4), progress.Loaded)
+			assert.Equal(t, int64(1), progress.Missing)
+			assert.Equal(t, int64(3), calls.Load())
+
+			var result string
+			found, err := s.Get(ctx, "d", &result)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, "value_d", result)
+		})
+	}
+
+	_, err := NewWarmer(newSyncStore(), echoFallback(new(atomic.Int64)), nil).Warm(ctx, FileKeys(filepath.Join(t.TempDir(), "none")))
+	assert.Error(t, err)
+}
+
+func TestWarmerErrorsAndProgress(t *testing.T) {
+	ctx := context.Background()
+	s := newSyncStore()
+
+	fallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		if slices.Contains(keys, "bad") {
+			return nil, errors.New("source unavailable")
+		}
+		result := make(map[string]interface{})
+		for _, key := range keys {
+			result[key] = key
+		}
+		return result, nil
+	}
+
+	var progresses []WarmProgress
+	var failedKeys []string
+	warmer := NewWarmer(s, fallback, &WarmerOptions{
+		ChunkSize:   2,
+		Parallelism: 1,
+		OnProgress: func(progress WarmProgress) {
+			progresses = append(progresses, progress)
+		},
+		OnError: func(keys []string, err error) {
+			assert.ErrorContains(t, err, "source unavailable")
+			failedKeys = append(failedKeys, keys...)
+		},
+	})
+
+	progress, err := warmer.Warm(ctx, SliceKeys([]string{"a", "b", "bad", "c", "d"}))
+	require.NoError(t, err)
+	assert.Equal(t, WarmProgress{Keys: 5, Loaded: 3, Failed: 2, Elapsed: progress.Elapsed}, progress)
+	assert.Equal(t, []string{"bad", "c"}, failedKeys)
+	require.Len(t, progresses, 3)
+	assert.Equal(t, int64(5), progresses[2].Keys)
+}
+
+func TestWarmerExtraKeys(t *testing.T) {
+	ctx := context.Background()
+	s := newSyncStore()
+
+	// 回退函数额外返回了未请求的键
+	fallback := func(ctx context.Context, keys []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"a": "a", "related_a": "r", "related_b": "r"}, nil
+	}
+
+	warmer := NewWarmer(s, fallback, nil)
+	progress, err := warmer.Warm(ctx, SliceKeys([]string{"a", "b"}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), progress.Loaded)
+	assert.Equal(t, int64(1), progress.Missing)
+}
+
+func TestWarmerTTLJitterAndSkipCached(t *testing.T) {
+	ctx := context.Background()
+	s := newSyncStore()
+	require.NoError(t, s.MSet(ctx, map[string]interface{}{"cached": "old"}, 0))
+	s.ttls = nil
+
+	keys := []string{"cached"}
+	for i := 0; i < 50; i++ {
+		keys = append(keys, fmt.Sprintf("key%d", i))
+	}
+
+	var calls atomic.Int64
+	warmer := NewWarmer(s, echoFallback(&calls), &WarmerOptions{
+		TTL:        time.Minute,
+		TTLJitter:  10 * time.Second,
+		ChunkSize:  5,
+		SkipCached: true,
+	})
+
+	progress, err := warmer.Warm(ctx, SliceKeys(keys))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), progress.Skipped)
+	assert.Equal(t, int64(50), progress.Loaded)
+
+	// 已缓存的键不会被覆盖
+	var result string
+	_, err = s.Get(ctx, "cached", &result)
+	require.NoError(t, err)
+	assert.Equal(t, "old", result)
+
+	// 每批的TTL在[TTL, TTL+TTLJitter)范围内且不完全相同
+	distinct := make(map[time.Duration]bool)
+	for _, ttl := range s.ttls {
+		assert.GreaterOrEqual(t, ttl, time.Minute)
+		assert.Less(t, ttl, time.Minute+10*time.Second)
+		distinct[ttl] = true
+	}
+	assert.Greater(t, len(distinct), 1)
+}
+
+func TestWarmerRateLimitAndCancel(t *testing.T) {
+	s := newSyncStore()
+	var calls atomic.Int64
+
+	// 每秒100个键，40个键至少需要约300ms(第一批立即放行)
+	warmer := NewWarmer(s, echoFallback(&calls), &WarmerOptions{ChunkSize: 10, Rate: 100})
+	keys := make([]string, 40)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+	}
+
+	start := time.Now()
+	_, err := warmer.Warm(context.Background(), SliceKeys(keys))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+
+	// 取消ctx后停止读取键并返回ctx的错误
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	endless := SeqKeys(func(yield func(string) bool) {
+		for i := 0; yield(fmt.Sprintf("endless%d", i)); i++ {
+		}
+	})
+	progress, err := warmer.Warm(ctx, endless)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, progress.Loaded, int64(100))
+}
@@ -0,0 +1,99 @@
+// cacheserver 通过Redis协议(RESP2)对外提供缓存服务，后端可以是任意store.Store
+//
+// 支持GET/MGET/SET(EX/PX)/MSET/DEL/EXISTS，可以直接使用redis-cli或
+// go-cache/cacher/store/redis连接：
+//
+//	cacheserver -addr :6380 -store bolt -path /var/lib/cache.db
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-cache/cacher/store"
+	"go-cache/cacher/store/bolt"
+	"go-cache/cacher/store/fs"
+	"go-cache/cacher/store/ristretto"
+)
+
+func main() {
+	addr := flag.String("addr", ":6380", "监听地址")
+	backend := flag.String("store", "ristretto", "存储后端: ristretto, bolt, fs")
+	path := flag.String("path", "", "bolt数据库文件或fs缓存目录")
+	maxConns := flag.Int("max-conns", 10000, "最大并发连接数，0表示不限制")
+	idleTimeout := flag.Duration("idle-timeout", 0, "连接空闲超时，0表示不超时")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 10*time.Second, "优雅关闭的最长等待时间")
+	flag.Parse()
+
+	s, err := openStore(*backend, *path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if closer, ok := store.As[store.Closer](s); ok {
+		defer closer.Close()
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server := NewServer(s, &ServerOptions{
+		MaxConns:    *maxConns,
+		IdleTimeout: *idleTimeout,
+	})
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+	log.Printf("cacheserver listening on %s (store=%s)", listener.Addr(), *backend)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-signals:
+		log.Printf("received %s, shutting down", sig)
+	case err := <-errCh:
+		log.Printf("serve error: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("shutdown error: %v", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, ErrServerClosed) {
+		log.Printf("serve error: %v", err)
+	}
+}
+
+// openStore 按名称创建存储后端，需要序列化的后端使用store.RawCodec透传客户端数据
+func openStore(name, path string) (store.Store, error) {
+	switch name {
+	case "ristretto":
+		return ristretto.NewStore()
+	case "bolt":
+		if path == "" {
+			return nil, fmt.Errorf("-path is required for bolt store")
+		}
+		return bolt.NewStore(path, &bolt.Options{Codec: store.RawCodec{}})
+	case "fs":
+		if path == "" {
+			return nil, fmt.Errorf("-path is required for fs store")
+		}
+		return fs.NewStore(path, &fs.Options{Codec: store.RawCodec{}})
+	default:
+		return nil, fmt.Errorf("unknown store %q", name)
+	}
+}
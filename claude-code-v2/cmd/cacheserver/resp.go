@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxLineSize 单行(数组头、批量字符串头或内联命令)的最大长度
+	maxLineSize = 64 << 10
+	// maxArgs 单条命令允许的最大参数数量
+	maxArgs = 64 << 10
+	// maxBulkSize 单个参数的最大字节数
+	maxBulkSize = 64 << 20
+	// maxPrealloc 按长度字段预先分配的上限，超出部分随实际读到的数据增长
+	maxPrealloc = 64 << 10
+)
+
+// errProtocol 客户端发送了无法解析的数据，连接需要关闭
+var errProtocol = errors.New("protocol error")
+
+// respReader RESP2命令读取器，同时支持多批量格式和内联命令
+type respReader struct {
+	r *bufio.Reader
+}
+
+func newRESPReader(r io.Reader) *respReader {
+	return &respReader{r: bufio.NewReaderSize(r, maxLineSize)}
+}
+
+// Buffered 返回已读入缓冲区但尚未解析的字节数，用于判断客户端是否在pipeline
+func (rr *respReader) Buffered() int {
+	return rr.r.Buffered()
+}
+
+// ReadCommand 读取一条命令，返回命令名及参数
+func (rr *respReader) ReadCommand() ([][]byte, error) {
+	line, err := rr.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+
+	// 内联命令，如telnet或redis-cli发送的纯文本
+	// line指向bufio.Reader的缓冲区，下次读取时会被覆盖，每个参数需要复制
+	if line[0] != '*' {
+		args := bytes.Fields(line)
+		for i, arg := range args {
+			args[i] = bytes.Clone(arg)
+		}
+		return args, nil
+	}
+
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n > maxArgs {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	// 长度字段由客户端控制，只预先分配有限的容量
+	args := make([][]byte, 0, min(n, maxPrealloc/8))
+	for range n {
+		header, err := rr.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(header) == 0 || header[0] != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got '%s'", errProtocol, header)
+		}
+
+		size, err := strconv.Atoi(string(header[1:]))
+		if err != nil || size < 0 || size > maxBulkSize {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+
+		// 每个参数单独分配，存储后端可能直接持有该切片
+		buf, err := rr.readBulk(size + 2)
+		if err != nil {
+			return nil, err
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", errProtocol)
+		}
+		args = append(args, buf[:size:size])
+	}
+
+	return args, nil
+}
+
+// readBulk 读取size字节
+// 缓冲区随实际读到的数据增长，客户端只发送长度字段时不会按其分配内存
+func (rr *respReader) readBulk(size int) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(min(size, maxPrealloc))
+	if _, err := io.CopyN(&buf, rr.r, int64(size)); err != nil {
+		if errors.Is(err, io.EOF) && buf.Len() > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readLine 读取一行并去掉行尾的CRLF
+func (rr *respReader) readLine() ([]byte, error) {
+	line, err := rr.r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("%w: line too long", errProtocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line = bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+	return line, nil
+}
+
+// respWriter RESP2响应写入器
+type respWriter struct {
+	w *bufio.Writer
+}
+
+func newRESPWriter(w io.Writer) *respWriter {
+	return &respWriter{w: bufio.NewWriter(w)}
+}
+
+// Flush 将缓冲的响应写入连接
+func (rw *respWriter) Flush() error {
+	return rw.w.Flush()
+}
+
+// WriteSimple 写入简单字符串，如+OK
+func (rw *respWriter) WriteSimple(s string) {
+	rw.w.WriteByte('+')
+	rw.w.WriteString(s)
+	rw.w.WriteString("\r\n")
+}
+
+// WriteError 写入错误，msg需要包含错误前缀，如"ERR ..."
+func (rw *respWriter) WriteError(msg string) {
+	rw.w.WriteByte('-')
+	rw.w.WriteString(msg)
+	rw.w.WriteString("\r\n")
+}
+
+// WriteInt 写入整数
+func (rw *respWriter) WriteInt(n int64) {
+	rw.w.WriteByte(':')
+	rw.w.WriteString(strconv.FormatInt(n, 10))
+	rw.w.WriteString("\r\n")
+}
+
+// WriteBulk 写入批量字符串，nil表示空回复
+func (rw *respWriter) WriteBulk(b []byte) {
+	if b == nil {
+		rw.w.WriteString("$-1\r\n")
+		return
+	}
+	rw.w.WriteByte('$')
+	rw.w.WriteString(strconv.Itoa(len(b)))
+	rw.w.WriteString("\r\n")
+	rw.w.Write(b)
+	rw.w.WriteString("\r\n")
+}
+
+// WriteArrayLen 写入数组头
+func (rw *respWriter) WriteArrayLen(n int) {
+	rw.w.WriteByte('*')
+	rw.w.WriteString(strconv.Itoa(n))
+	rw.w.WriteString("\r\n")
+}
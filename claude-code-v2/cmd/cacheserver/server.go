@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-cache/cacher/store"
+)
+
+// ErrServerClosed Serve在服务器关闭后返回的错误
+var ErrServerClosed = errors.New("cacheserver: server closed")
+
+// ServerOptions 服务器配置
+type ServerOptions struct {
+	// MaxConns 最大并发连接数，0表示不限制
+	MaxConns int
+
+	// IdleTimeout 连接空闲超时，0表示不超时
+	IdleTimeout time.Duration
+}
+
+// Server 通过RESP2协议对外提供任意store.Store的缓存服务器
+// 值以原始字节透传，客户端写入什么就返回什么，因此底层Store应直接保存[]byte
+// (如Ristretto)或使用store.RawCodec。
+type Server struct {
+	store store.Store
+	opts  ServerOptions
+
+	mutex    sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+
+	closing atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// NewServer 创建新的缓存服务器
+// opts: 配置项，可以为nil使用默认配置
+func NewServer(s store.Store, opts *ServerOptions) *Server {
+	var o ServerOptions
+	if opts != nil {
+		o = *opts
+	}
+
+	return &Server{
+		store: s,
+		opts:  o,
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve 在listener上接受连接并处理请求，直到Shutdown被调用
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.closing.Load() {
+		s.mutex.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if s.closing.Load() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			// 与Redis一致，超出连接数限制时返回错误后关闭连接
+			conn.Write([]byte("-ERR max number of clients reached\r\n"))
+			conn.Close()
+			continue
+		}
+
+		go s.handle(conn)
+	}
+}
+
+// Shutdown 优雅关闭服务器：停止接受新连接，等待正在执行的命令完成后关闭连接
+// ctx到期时强制关闭剩余连接并返回ctx的错误
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.closing.Store(true)
+
+	s.mutex.Lock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	// 唤醒阻塞在读取上的空闲连接
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mutex.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mutex.Unlock()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// track 登记新连接，超出连接数限制或服务器正在关闭时返回false
+func (s *Server) track(conn net.Conn) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closing.Load() {
+		return false
+	}
+	if s.opts.MaxConns > 0 && len(s.conns) >= s.opts.MaxConns {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+// untrack 移除已关闭的连接
+func (s *Server) untrack(conn net.Conn) {
+	s.mutex.Lock()
+	delete(s.conns, conn)
+	s.mutex.Unlock()
+	s.wg.Done()
+}
+
+// handle 处理单个连接上的命令，支持客户端pipeline
+func (s *Server) handle(conn net.Conn) {
+	defer s.untrack(conn)
+	defer conn.Close()
+
+	reader := newRESPReader(conn)
+	writer := newRESPWriter(conn)
+	ctx := context.Background()
+
+	for {
+		if s.opts.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.opts.IdleTimeout))
+		}
+		// 必须在设置超时之后检查，保证Shutdown设置的超时不会被覆盖
+		if s.closing.Load() {
+			return
+		}
+
+		args, err := reader.ReadCommand()
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				writer.WriteError("ERR " + err.Error())
+				writer.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := s.execute(ctx, writer, args)
+
+		// 客户端pipeline发送的命令全部处理完后再统一刷新
+		if quit || reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// execute 执行一条命令并写入响应，返回是否需要关闭连接
+func (s *Server) execute(ctx context.Context, w *respWriter, args [][]byte) bool {
+	name := strings.ToUpper(string(args[0]))
+	args = args[1:]
+
+	switch name {
+	case "PING":
+		// 后端实现store.Pinger时一并检查后端是否可用
+		if pinger, ok := store.As[store.Pinger](s.store); ok {
+			if err := pinger.Ping(ctx); err != nil {
+				writeStoreError(w, err)
+				return false
+			}
+		}
+		if len(args) > 0 {
+			w.WriteBulk(args[0])
+		} else {
+			w.WriteSimple("PONG")
+		}
+	case "ECHO":
+		if len(args) != 1 {
+			writeArityError(w, name)
+			return false
+		}
+		w.WriteBulk(args[0])
+	case "QUIT":
+		w.WriteSimple("OK")
+		return true
+	case "SELECT":
+		if len(args) != 1 || string(args[0]) != "0" {
+			w.WriteError("ERR DB index is out of range")
+			return false
+		}
+		w.WriteSimple("OK")
+	case "CLIENT":
+		// 客户端库连接时会发送CLIENT SETNAME/SETINFO，直接忽略
+		w.WriteSimple("OK")
+	case "COMMAND":
+		// redis-cli启动时会查询命令文档，返回空列表即可
+		w.WriteArrayLen(0)
+	case "GET":
+		s.cmdGet(ctx, w, args)
+	case "MGET":
+		s.cmdMGet(ctx, w, args)
+	case "SET":
+		s.cmdSet(ctx, w, args)
+	case "MSET":
+		s.cmdMSet(ctx, w, args)
+	case "DEL", "UNLINK":
+		s.cmdDel(ctx, w, args)
+	case "EXISTS":
+		s.cmdExists(ctx, w, args)
+	case "FLUSHDB", "FLUSHALL":
+		s.cmdFlush(ctx, w, name, args)
+	default:
+		// HELLO同样返回未知命令，客户端会据此回退到RESP2
+		w.WriteError(fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(name)))
+	}
+
+	return false
+}
+
+func (s *Server) cmdGet(ctx context.Context, w *respWriter, args [][]byte) {
+	if len(args) != 1 {
+		writeArityError(w, "GET")
+		return
+	}
+
+	var value []byte
+	found, err := s.store.Get(ctx, string(args[0]), &value)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeValue(w, value, found)
+}
+
+func (s *Server) cmdMGet(ctx context.Context, w *respWriter, args [][]byte) {
+	if len(args) == 0 {
+		writeArityError(w, "MGET")
+		return
+	}
+
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg)
+	}
+
+	// 无法解码的键按不存在返回
+	values := make(map[string][]byte, len(keys))
+	var batchErr *store.BatchError
+	if err := s.store.MGet(ctx, keys, &values); err != nil && !errors.As(err, &batchErr) {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteArrayLen(len(keys))
+	for _, key := range keys {
+		value, found := values[key]
+		writeValue(w, value, found)
+	}
+}
+
+func (s *Server) cmdSet(ctx context.Context, w *respWriter, args [][]byte) {
+	if len(args) < 2 {
+		writeArityError(w, "SET")
+		return
+	}
+
+	var ttl time.Duration
+	for i := 2; i < len(args); i++ {
+		option := strings.ToUpper(string(args[i]))
+		if (option != "EX" && option != "PX") || i+1 >= len(args) {
+			w.WriteError("ERR syntax error")
+			return
+		}
+
+		n, err := strconv.ParseInt(string(args[i+1]), 10, 64)
+		if err != nil || n <= 0 {
+			w.WriteError("ERR invalid expire time in 'set' command")
+			return
+		}
+		if option == "EX" {
+			ttl = time.Duration(n) * time.Second
+		} else {
+			ttl = time.Duration(n) * time.Millisecond
+		}
+		i++
+	}
+
+	if err := s.store.MSet(ctx, map[string]interface{}{string(args[0]): args[1]}, ttl); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+func (s *Server) cmdMSet(ctx context.Context, w *respWriter, args [][]byte) {
+	if len(args) == 0 || len(args)%2 != 0 {
+		writeArityError(w, "MSET")
+		return
+	}
+
+	items := make(map[string]interface{}, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		items[string(args[i])] = args[i+1]
+	}
+
+	if err := s.store.MSet(ctx, items, 0); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+func (s *Server) cmdDel(ctx context.Context, w *respWriter, args [][]byte) {
+	if len(args) == 0 {
+		writeArityError(w, "DEL")
+		return
+	}
+
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg)
+	}
+
+	deleted, err := s.store.Del(ctx, keys...)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteInt(deleted)
+}
+
+func (s *Server) cmdExists(ctx context.Context, w *respWriter, args [][]byte) {
+	if len(args) == 0 {
+		writeArityError(w, "EXISTS")
+		return
+	}
+
+	keys := make([]string, len(args))
+	for i, arg := range args {
+		keys[i] = string(arg)
+	}
+
+	exists, err := s.store.Exists(ctx, keys)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	// 与Redis一致，重复的键会被重复计数
+	var count int64
+	for _, key := range keys {
+		if exists[key] {
+			count++
+		}
+	}
+	w.WriteInt(count)
+}
+
+func (s *Server) cmdFlush(ctx context.Context, w *respWriter, name string, args [][]byte) {
+	// 接受ASYNC/SYNC选项，均同步执行
+	if len(args) > 1 {
+		writeArityError(w, name)
+		return
+	}
+	if len(args) == 1 {
+		mode := strings.ToUpper(string(args[0]))
+		if mode != "ASYNC" && mode != "SYNC" {
+			w.WriteError("ERR syntax error")
+			return
+		}
+	}
+
+	flusher, ok := store.As[store.Flusher](s.store)
+	if !ok {
+		w.WriteError(fmt.Sprintf("ERR %s is not supported by this store", strings.ToLower(name)))
+		return
+	}
+	if err := flusher.Flush(ctx); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteSimple("OK")
+}
+
+// writeValue 写入查询结果，未找到时写入空回复
+func writeValue(w *respWriter, value []byte, found bool) {
+	if !found {
+		w.WriteBulk(nil)
+		return
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	w.WriteBulk(value)
+}
+
+// writeArityError 写入参数数量错误
+func writeArityError(w *respWriter, name string) {
+	w.WriteError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+}
+
+// writeStoreError 写入存储后端错误，去掉换行避免破坏协议
+func writeStoreError(w *respWriter, err error) {
+	msg := strings.NewReplacer("\r", " ", "\n", " ").Replace(err.Error())
+	w.WriteError("ERR " + msg)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-cache/cacher/store"
+	"go-cache/cacher/store/bolt"
+	redisstore "go-cache/cacher/store/redis"
+	"go-cache/cacher/store/ristretto"
+)
+
+// startServer 在随机端口启动服务器，返回监听地址
+func startServer(t *testing.T, s store.Store, opts *ServerOptions) (*Server, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer(s, opts)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+		assert.ErrorIs(t, <-errCh, ErrServerClosed)
+	})
+
+	return server, listener.Addr().String()
+}
+
+// newClient 创建连接到服务器的Redis客户端
+func newClient(t *testing.T, addr string) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestServerRistretto(t *testing.T) {
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	_, addr := startServer(t, ristrettoStore, nil)
+
+	// 通过Redis Store访问服务器，运行通用测试套件
+	redisStore := redisstore.NewStore(newClient(t, addr))
+	testHelper := store.NewTestHelper(t, redisStore)
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+	assert.NoError(t, redisStore.Ping(context.Background()))
+}
+
+func TestServerBolt(t *testing.T) {
+	boltStore, err := bolt.NewStore(filepath.Join(t.TempDir(), "cache.db"), &bolt.Options{Codec: store.RawCodec{}})
+	require.NoError(t, err)
+	defer boltStore.Close()
+
+	_, addr := startServer(t, boltStore, nil)
+
+	testHelper := store.NewTestHelper(t, redisstore.NewStore(newClient(t, addr)))
+	testHelper.RunAllTests()
+	testHelper.TestFlusher()
+}
+
+func TestServerCommands(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	_, addr := startServer(t, ristrettoStore, nil)
+	client := newClient(t, addr)
+
+	assert.Equal(t, "PONG", client.Ping(ctx).Val())
+	require.NoError(t, client.Set(ctx, "a", "1", 0).Err())
+	require.NoError(t, client.Set(ctx, "b", "", 0).Err())
+
+	// 空值与不存在的键需要区分
+	val, err := client.Get(ctx, "b").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "", val)
+	_, err = client.Get(ctx, "missing").Result()
+	assert.Equal(t, redis.Nil, err)
+
+	assert.Equal(t, []interface{}{"1", nil, ""}, client.MGet(ctx, "a", "missing", "b").Val())
+	assert.Equal(t, int64(3), client.Exists(ctx, "a", "a", "b", "missing").Val())
+
+	// PX过期
+	require.NoError(t, client.Set(ctx, "short", "x", 50*time.Millisecond).Err())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(0), client.Exists(ctx, "short").Val())
+
+	assert.Error(t, client.Do(ctx, "SET", "a", "1", "NX").Err())
+	assert.Error(t, client.Do(ctx, "SET", "a", "1", "EX", "0").Err())
+	assert.Error(t, client.Do(ctx, "HGET", "a", "f").Err())
+
+	assert.Equal(t, int64(2), client.Del(ctx, "a", "b", "missing").Val())
+}
+
+func TestServerInlineAndPipeline(t *testing.T) {
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	_, addr := startServer(t, ristrettoStore, nil)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// 内联命令与多批量命令混合在一次写入中
+	_, err = conn.Write([]byte("SET k v\r\n*2\r\n$3\r\nGET\r\n$1\r\nk\r\nPING\r\n"))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	for _, expected := range []string{"+OK\r\n", "$1\r\n", "v\r\n", "+PONG\r\n"} {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+
+	// 协议错误时返回错误并关闭连接
+	_, err = conn.Write([]byte("*1\r\n:1\r\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, "-ERR protocol error")
+	_, err = reader.ReadString('\n')
+	assert.Error(t, err)
+}
+
+func TestServerInlineArgsCopied(t *testing.T) {
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	_, addr := startServer(t, ristrettoStore, nil)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	// 每条命令单独写入，后续命令会复用读缓冲区中内联SET所在的位置
+	for _, command := range []string{"SET k v\r\n", "SET z w\r\n"} {
+		_, err = conn.Write([]byte(command))
+		require.NoError(t, err)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "+OK\r\n", line)
+	}
+
+	_, err = conn.Write([]byte("GET k\r\n"))
+	require.NoError(t, err)
+	for _, expected := range []string{"$1\r\n", "v\r\n"} {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, expected, line)
+	}
+}
+
+func TestRESPReaderBoundedAlloc(t *testing.T) {
+	// 只发送长度字段时不按其分配内存
+	for _, input := range []string{
+		"*1\r\n$67108863\r\nabc",
+		"*65536\r\n$1\r\na\r\n",
+	} {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := newRESPReader(strings.NewReader(input)).ReadCommand()
+		runtime.ReadMemStats(&after)
+		assert.Error(t, err)
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(1<<20))
+	}
+
+	// 超过上限的长度字段直接拒绝
+	_, err := newRESPReader(strings.NewReader("*1\r\n$67108865\r\n")).ReadCommand()
+	assert.ErrorIs(t, err, errProtocol)
+}
+
+func TestServerMaxConns(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	_, addr := startServer(t, ristrettoStore, &ServerOptions{MaxConns: 1})
+
+	first, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer first.Close()
+	_, err = first.Write([]byte("PING\r\n"))
+	require.NoError(t, err)
+	line, err := bufio.NewReader(first).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "+PONG\r\n", line)
+
+	// 超出连接数限制的连接收到错误后被关闭
+	second, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer second.Close()
+	line, err = bufio.NewReader(second).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "-ERR max number of clients reached\r\n", line)
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	defer client.Close()
+
+	// 释放连接后可以重新连接
+	first.Close()
+	assert.Eventually(t, func() bool {
+		return client.Ping(ctx).Err() == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServerShutdown(t *testing.T) {
+	ctx := context.Background()
+	ristrettoStore, err := ristretto.NewStore()
+	require.NoError(t, err)
+	defer ristrettoStore.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := NewServer(ristrettoStore, nil)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), MaxRetries: -1})
+	defer client.Close()
+	require.NoError(t, client.Set(ctx, "k", "v", 0).Err())
+
+	// 空闲连接会被及时关闭，Shutdown不需要等到超时
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, server.Shutdown(shutdownCtx))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, errors.Is(<-errCh, ErrServerClosed))
+
+	// 关闭后不再接受连接
+	assert.Error(t, client.Ping(ctx).Err())
+}